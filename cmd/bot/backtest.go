@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"trading-bot/internal/backtest"
+	"trading-bot/internal/bot"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/market"
+	"trading-bot/internal/metrics"
+)
+
+func runBacktest(ctx context.Context, config *bot.Config, args []string) error {
+	flags := flag.NewFlagSet("backtest", flag.ExitOnError)
+	strategyName := flags.String("strategy", config.Trading.Strategy, "strategy to test")
+	dataFile := flags.String("data", "", "CSV file of candles; fetched from Binance when empty")
+	interval := flags.String("interval", "1m", "kline interval to fetch")
+	days := flags.Int("days", 7, "number of days of klines to fetch")
+	saveFile := flags.String("save", "", "write the fetched candles to this CSV file")
+	equityFile := flags.String("equity", "", "write the equity curve to this CSV file")
+	flags.Parse(args)
+
+	config.Trading.Strategy = *strategyName
+	config.Bot.DryRun = true
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	candles, err := loadCandles(ctx, config, *dataFile, *interval, *days)
+	if err != nil {
+		return err
+	}
+
+	if *saveFile != "" {
+		if err := market.SaveCandlesCSV(*saveFile, candles); err != nil {
+			return err
+		}
+	}
+
+	strat, err := bot.NewStrategy(config.Trading.Strategy, config)
+	if err != nil {
+		return fmt.Errorf("failed to create strategy: %w", err)
+	}
+
+	result, err := backtest.Run(strat, candles, backtest.Config{
+		Symbol:         config.Trading.Symbol,
+		InitialBalance: config.Trading.InitialBalance,
+		CostMethod:     config.CostMethod(),
+	})
+	if err != nil {
+		return err
+	}
+
+	result.Print(os.Stdout)
+
+	if *equityFile != "" {
+		if err := metrics.SaveSamplesCSV(*equityFile, result.Samples); err != nil {
+			return err
+		}
+		fmt.Printf("Equity curve written to %s\n", *equityFile)
+	}
+	return nil
+}
+
+func loadCandles(ctx context.Context, config *bot.Config, dataFile, interval string, days int) ([]market.Candle, error) {
+	if dataFile != "" {
+		return market.LoadCandlesCSV(dataFile, config.Trading.Symbol)
+	}
+
+	client, err := exchange.NewBinanceClient(config.Binance.APIKey, config.Binance.SecretKey, config.Binance.TestNet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create exchange client: %w", err)
+	}
+
+	end := time.Now()
+	candles, err := client.GetKlinesRange(ctx, config.Trading.Symbol, interval, end.AddDate(0, 0, -days), end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch klines: %w", err)
+	}
+	return candles, nil
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -32,29 +34,68 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	if err := config.Validate(); err != nil {
+	logger, err := bot.NewLogger(config, os.Stderr)
+	if err != nil {
 		log.Fatalf("Invalid configuration: %v", err)
 	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backtest":
+			if err := runBacktest(ctx, config, os.Args[2:]); err != nil {
+				fatal("Backtest error", err)
+			}
+			return
+		case "optimize":
+			if err := runOptimize(ctx, config, os.Args[2:]); err != nil {
+				fatal("Optimization error", err)
+			}
+			return
+		case "walkforward":
+			if err := runWalkForward(ctx, config, os.Args[2:]); err != nil {
+				fatal("Walk-forward error", err)
+			}
+			return
+		case "tui":
+			if err := runTUI(ctx, config, os.Args[2:]); err != nil {
+				fatal("Dashboard error", err)
+			}
+			return
+		default:
+			slog.Error("Unknown command", "command", os.Args[1])
+			os.Exit(1)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		fatal("Invalid configuration", err)
+	}
 
 	tradingBot, err := bot.NewTradingBot(config)
 	if err != nil {
-		log.Fatalf("Failed to create trading bot: %v", err)
+		fatal("Failed to create trading bot", err)
 	}
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-
 	go func() {
-		<-c
-		log.Println("Shutdown signal received...")
-		tradingBot.Stop()
+		<-ctx.Done()
+		slog.Info("Shutdown signal received")
 	}()
 
 	fmt.Printf("Starting Trading Bot for %s...\n", config.Trading.Symbol)
 	fmt.Printf("Strategy: %s\n", config.Trading.Strategy)
 	fmt.Printf("Dry Run: %v\n", config.Bot.DryRun)
 
-	if err := tradingBot.Start(); err != nil {
-		log.Fatalf("Trading bot error: %v", err)
+	if err := tradingBot.Start(ctx); err != nil {
+		fatal("Trading bot error", err)
 	}
 }
+
+// fatal logs err through the configured logger and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"trading-bot/internal/bot"
+	"trading-bot/internal/optimize"
+)
+
+func runOptimize(ctx context.Context, config *bot.Config, args []string) error {
+	flags := flag.NewFlagSet("optimize", flag.ExitOnError)
+	specFile := flags.String("spec", "configs/optimize.json", "optimization spec")
+	dataFile := flags.String("data", "", "CSV file of candles; fetched from Binance when empty")
+	interval := flags.String("interval", "1m", "kline interval to fetch")
+	days := flags.Int("days", 7, "number of days of klines to fetch")
+	outFile := flags.String("out", "optimize_results.csv", "results table")
+	workers := flags.Int("workers", 0, "parallel backtests (0 uses every CPU)")
+	top := flags.Int("top", 10, "number of results to print")
+	flags.Parse(args)
+
+	spec, err := optimize.LoadSpec(*specFile)
+	if err != nil {
+		return err
+	}
+
+	candles, err := loadCandles(ctx, config, *dataFile, *interval, *days)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	trials := optimize.Run(spec, config, candles, *workers)
+	fmt.Printf("Ran %d backtests over %d candles in %s\n", len(trials), len(candles), time.Since(start).Round(time.Millisecond))
+
+	optimize.PrintTop(os.Stdout, spec, trials, *top)
+	if err := optimize.WriteCSVFile(*outFile, spec, trials); err != nil {
+		return err
+	}
+	fmt.Printf("Results written to %s\n", *outFile)
+	return nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"trading-bot/internal/bot"
+	"trading-bot/internal/tui"
+)
+
+// runTUI runs the bot behind a live dashboard. Logs and periodic summaries
+// would scroll over the screen, so they go to a file instead.
+func runTUI(ctx context.Context, config *bot.Config, args []string) error {
+	flags := flag.NewFlagSet("tui", flag.ExitOnError)
+	refresh := flags.Duration("refresh", time.Second, "screen refresh interval")
+	logFile := flags.String("log", "data/tui.log", "file for logs and periodic summaries")
+	width := flags.Int("width", 100, "dashboard width in columns")
+	flags.Parse(args)
+
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*logFile), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	defer file.Close()
+
+	logger, err := bot.NewLogger(config, file)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	tradingBot, err := bot.NewTradingBot(config)
+	if err != nil {
+		return fmt.Errorf("failed to create trading bot: %w", err)
+	}
+	tradingBot.SetOutput(file)
+
+	dashboard := tui.New(tradingBot, os.Stdout, tui.Options{
+		Refresh: *refresh,
+		Width:   *width,
+		Color:   os.Getenv("NO_COLOR") == "",
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tradingBot.Start(ctx)
+	}()
+
+	if err := dashboard.Run(ctx); err != nil {
+		tradingBot.Stop()
+		<-done
+		return fmt.Errorf("dashboard failed: %w", err)
+	}
+
+	err = <-done
+	fmt.Printf("Trading bot stopped. Logs and summaries are in %s\n", *logFile)
+	return err
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"trading-bot/internal/bot"
+	"trading-bot/internal/market"
+	"trading-bot/internal/optimize"
+)
+
+func runWalkForward(ctx context.Context, config *bot.Config, args []string) error {
+	flags := flag.NewFlagSet("walkforward", flag.ExitOnError)
+	specFile := flags.String("spec", "configs/optimize.json", "optimization spec")
+	dataFile := flags.String("data", "", "CSV file of candles; fetched from Binance when empty")
+	interval := flags.String("interval", "1m", "kline interval to fetch")
+	days := flags.Int("days", 30, "number of days of klines to fetch")
+	inSample := flags.String("in-sample", "3d", "length of each in-sample window")
+	outOfSample := flags.String("out-of-sample", "1d", "length of each out-of-sample window")
+	anchored := flags.Bool("anchored", false, "grow in-sample windows from the first candle")
+	outFile := flags.String("out", "walkforward_results.csv", "per-window results table")
+	workers := flags.Int("workers", 0, "parallel backtests (0 uses every CPU)")
+	flags.Parse(args)
+
+	spec, err := optimize.LoadSpec(*specFile)
+	if err != nil {
+		return err
+	}
+
+	candles, err := loadCandles(ctx, config, *dataFile, *interval, *days)
+	if err != nil {
+		return err
+	}
+	if len(candles) < 2 {
+		return fmt.Errorf("need at least 2 candles, have %d", len(candles))
+	}
+
+	inBars, err := windowBars(*inSample, candles)
+	if err != nil {
+		return fmt.Errorf("in-sample: %w", err)
+	}
+	outBars, err := windowBars(*outOfSample, candles)
+	if err != nil {
+		return fmt.Errorf("out-of-sample: %w", err)
+	}
+
+	start := time.Now()
+	result, err := optimize.WalkForward(spec, config, candles, optimize.WalkForwardConfig{
+		InSample:    inBars,
+		OutOfSample: outBars,
+		Anchored:    *anchored,
+		Workers:     *workers,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Walk-forward over %d candles (%d in-sample / %d out-of-sample bars) in %s\n",
+		len(candles), inBars, outBars, time.Since(start).Round(time.Millisecond))
+
+	result.Print(os.Stdout)
+	if err := result.WriteWindowsCSV(*outFile, spec); err != nil {
+		return err
+	}
+	fmt.Printf("Window results written to %s\n", *outFile)
+	return nil
+}
+
+// windowBars converts a window length such as "3d" into a bar count using
+// the spacing of the loaded candles.
+func windowBars(length string, candles []market.Candle) (int, error) {
+	duration, err := market.ParseInterval(length)
+	if err != nil {
+		return 0, err
+	}
+
+	spacing := candles[1].OpenTime.Sub(candles[0].OpenTime)
+	if spacing <= 0 {
+		return 0, fmt.Errorf("candles are not in time order")
+	}
+
+	bars := int(duration / spacing)
+	if bars <= 0 {
+		return 0, fmt.Errorf("window %s is shorter than one %s candle", length, spacing)
+	}
+	return bars, nil
+}
@@ -0,0 +1,97 @@
+// Package api serves a local HTTP/JSON API for querying and controlling a
+// running bot.
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"trading-bot/internal/decimal"
+)
+
+// ErrStopped is returned by controllers that have shut down.
+var ErrStopped = errors.New("bot is stopped")
+
+// Controller is what the API queries and commands. Methods may block until
+// the bot is between ticks and should give up when ctx is done.
+type Controller interface {
+	Status(ctx context.Context) (Status, error)
+	Pause(ctx context.Context) error
+	Resume(ctx context.Context) error
+	// Flatten cancels open orders and sells the traded position, returning
+	// the resulting transactions.
+	Flatten(ctx context.Context) ([]Transaction, error)
+	SetDryRun(ctx context.Context, dryRun bool) error
+	// Stop begins a graceful shutdown and returns without waiting for it.
+	Stop()
+}
+
+// Status is a point-in-time view of the bot. Equity, prices and unrealized
+// P&L are in ReportingCurrency; RealizedPnL is in the cash asset.
+type Status struct {
+	Symbol             string                     `json:"symbol"`
+	Strategy           string                     `json:"strategy"`
+	DryRun             bool                       `json:"dry_run"`
+	Paused             bool                       `json:"paused"`
+	Halted             bool                       `json:"halted"`
+	MaxRisk            float64                    `json:"max_risk"`
+	Signal             *Signal                    `json:"signal,omitempty"`
+	LastPrice          decimal.Decimal            `json:"last_price"`
+	LastTick           time.Time                  `json:"last_tick"`
+	CashAsset          string                     `json:"cash_asset"`
+	ReportingCurrency  string                     `json:"reporting_currency"`
+	Balances           map[string]decimal.Decimal `json:"balances"`
+	Positions          []Position                 `json:"positions"`
+	OpenOrders         []Order                    `json:"open_orders"`
+	Equity             float64                    `json:"equity"`
+	Drawdown           float64                    `json:"drawdown"`
+	RealizedPnL        decimal.Decimal            `json:"realized_pnl"`
+	UnrealizedPnL      float64                    `json:"unrealized_pnl"`
+	RecentTransactions []Transaction              `json:"recent_transactions"`
+}
+
+// Signal is the strategy's latest output, holds included, with the
+// indicator values behind it.
+type Signal struct {
+	Action     string             `json:"action"`
+	Confidence float64            `json:"confidence"`
+	Reason     string             `json:"reason,omitempty"`
+	Indicators map[string]float64 `json:"indicators,omitempty"`
+	Time       time.Time          `json:"time"`
+}
+
+type Position struct {
+	Asset         string          `json:"asset"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Quote         string          `json:"quote"`
+	AverageCost   decimal.Decimal `json:"average_cost"`
+	Price         float64         `json:"price"`
+	MarketValue   float64         `json:"market_value"`
+	UnrealizedPnL float64         `json:"unrealized_pnl"`
+}
+
+type Order struct {
+	ID          int64           `json:"id"`
+	Symbol      string          `json:"symbol"`
+	Side        string          `json:"side"`
+	Type        string          `json:"type"`
+	Status      string          `json:"status"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	Price       decimal.Decimal `json:"price"`
+	ExecutedQty decimal.Decimal `json:"executed_quantity"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type Transaction struct {
+	Time        time.Time       `json:"time"`
+	Type        string          `json:"type"`
+	Symbol      string          `json:"symbol"`
+	Quote       string          `json:"quote,omitempty"`
+	Amount      decimal.Decimal `json:"amount"`
+	Price       decimal.Decimal `json:"price"`
+	Total       decimal.Decimal `json:"total"`
+	RealizedPnL decimal.Decimal `json:"realized_pnl"`
+	Strategy    string          `json:"strategy,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Server exposes a Controller over HTTP. Every request must carry the token
+// as "Authorization: Bearer <token>".
+type Server struct {
+	controller Controller
+	token      string
+	logger     *slog.Logger
+	server     *http.Server
+}
+
+func NewServer(controller Controller, token string, logger *slog.Logger) *Server {
+	return &Server{
+		controller: controller,
+		token:      token,
+		logger:     logger,
+	}
+}
+
+// Handler routes the API's endpoints behind token authentication.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/status", s.handleStatus)
+	mux.HandleFunc("POST /api/v1/pause", s.handlePause)
+	mux.HandleFunc("POST /api/v1/resume", s.handleResume)
+	mux.HandleFunc("POST /api/v1/flatten", s.handleFlatten)
+	mux.HandleFunc("POST /api/v1/mode", s.handleMode)
+	mux.HandleFunc("POST /api/v1/stop", s.handleStop)
+	return s.authenticate(mux)
+}
+
+// Serve listens on address and serves in the background. Listening happens
+// before it returns so a bad address fails startup.
+func (s *Server) Serve(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	s.server = &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("API server failed", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops accepting requests and waits for those in flight.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="trading-bot"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.controller.Status(r.Context())
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.command(w, r, "pause", s.controller.Pause)
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.command(w, r, "resume", s.controller.Resume)
+}
+
+func (s *Server) handleFlatten(w http.ResponseWriter, r *http.Request) {
+	s.logger.Info("API command", "command", "flatten", "remote", r.RemoteAddr)
+	transactions, err := s.controller.Flatten(r.Context())
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if transactions == nil {
+		transactions = []Transaction{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"transactions": transactions})
+}
+
+func (s *Server) handleMode(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		DryRun *bool `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.DryRun == nil {
+		writeError(w, http.StatusBadRequest, errors.New(`body must be {"dry_run": true|false}`))
+		return
+	}
+
+	dryRun := *body.DryRun
+	s.command(w, r, "mode", func(ctx context.Context) error {
+		return s.controller.SetDryRun(ctx, dryRun)
+	})
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	s.logger.Info("API command", "command", "stop", "remote", r.RemoteAddr)
+	s.controller.Stop()
+	writeJSON(w, http.StatusAccepted, map[string]string{"result": "stopping"})
+}
+
+func (s *Server) command(w http.ResponseWriter, r *http.Request, name string, run func(context.Context) error) {
+	s.logger.Info("API command", "command", name, "remote", r.RemoteAddr)
+	if err := run(r.Context()); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"result": "ok"})
+}
+
+// fail maps controller errors to responses: 503 once the bot has stopped
+// or the request gave up waiting, 409 for commands the bot refused.
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusConflict
+	if errors.Is(err, ErrStopped) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusServiceUnavailable
+	}
+	s.logger.Warn("API request failed", "path", r.URL.Path, "status", status, "error", err)
+	writeError(w, status, err)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
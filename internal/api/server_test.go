@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubController counts the commands it receives.
+type stubController struct {
+	pauses int
+}
+
+func (c *stubController) Status(ctx context.Context) (Status, error) {
+	return Status{Symbol: "BTCUSDT"}, nil
+}
+
+func (c *stubController) Pause(ctx context.Context) error {
+	c.pauses++
+	return nil
+}
+
+func (c *stubController) Resume(ctx context.Context) error { return nil }
+
+func (c *stubController) Flatten(ctx context.Context) ([]Transaction, error) { return nil, nil }
+
+func (c *stubController) SetDryRun(ctx context.Context, dryRun bool) error { return nil }
+
+func (c *stubController) Stop() {}
+
+func TestBearerAuthentication(t *testing.T) {
+	const token = "0123456789abcdef-secret"
+
+	tests := []struct {
+		name          string
+		method, path  string
+		authorization string
+		want          int
+	}{
+		{name: "status without a token", method: "GET", path: "/api/v1/status", want: http.StatusUnauthorized},
+		{name: "status with a wrong token", method: "GET", path: "/api/v1/status", authorization: "Bearer wrong", want: http.StatusUnauthorized},
+		{name: "token without the bearer scheme", method: "GET", path: "/api/v1/status", authorization: token, want: http.StatusUnauthorized},
+		{name: "token as basic credentials", method: "GET", path: "/api/v1/status", authorization: "Basic " + token, want: http.StatusUnauthorized},
+		{name: "status with the token", method: "GET", path: "/api/v1/status", authorization: "Bearer " + token, want: http.StatusOK},
+		{name: "pause without a token", method: "POST", path: "/api/v1/pause", want: http.StatusUnauthorized},
+		{name: "pause with the token", method: "POST", path: "/api/v1/pause", authorization: "Bearer " + token, want: http.StatusOK},
+		{name: "unknown path without a token", method: "GET", path: "/api/v1/unknown", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &stubController{}
+			handler := NewServer(controller, token, slog.New(slog.DiscardHandler)).Handler()
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status code = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Code == http.StatusUnauthorized {
+				if rec.Header().Get("WWW-Authenticate") == "" {
+					t.Error("401 without a WWW-Authenticate challenge")
+				}
+				if controller.pauses != 0 {
+					t.Error("an unauthenticated request reached the controller")
+				}
+			}
+		})
+	}
+}
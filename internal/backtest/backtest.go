@@ -0,0 +1,223 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/market"
+	"trading-bot/internal/metrics"
+	"trading-bot/internal/portfolio"
+	"trading-bot/internal/strategy"
+)
+
+// Config describes a replay. WarmUp candles, typically the bars just before
+// the replayed ones, prime the strategy without trading. CostMethod defaults
+// to FIFO.
+type Config struct {
+	Symbol         string
+	InitialBalance float64
+	CostMethod     portfolio.CostMethod
+	WarmUp         []market.Candle
+}
+
+type Result struct {
+	Strategy       string
+	Start          time.Time
+	End            time.Time
+	Bars           int
+	Currency       string
+	InitialBalance float64
+	FinalEquity    float64
+	NetProfit      float64
+	ReturnPct      float64
+	Trades         []portfolio.Transaction
+	Equity         []metrics.EquityPoint
+	Samples        []metrics.Sample
+
+	metrics.Report
+}
+
+const historySize = 500
+
+// Run replays candles through a strategy the way the bot trades in dry-run
+// mode: each candle's close is delivered as a tick at its close time and
+// signals fill at that price. Candle strategies receive closed candles of
+// their own interval and multi-timeframe strategies receive closed bars, so
+// no decision can see data from after its own bar.
+func Run(strat strategy.Strategy, candles []market.Candle, config Config) (*Result, error) {
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("no candles to replay")
+	}
+
+	asset, quote := market.SplitSymbol(config.Symbol)
+	if quote == "" {
+		quote = portfolio.DefaultCash
+	}
+
+	initialBalance, err := decimal.FromFloat(config.InitialBalance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid initial balance: %w", err)
+	}
+
+	// Balances and equity are kept in the quote asset.
+	var now time.Time
+	p := portfolio.NewPortfolioWithBalances(map[string]decimal.Decimal{quote: initialBalance}, quote)
+	p.SetClock(func() time.Time { return now })
+	p.SetLogger(slog.New(slog.DiscardHandler))
+	if config.CostMethod != "" {
+		p.SetCostMethod(config.CostMethod)
+	}
+
+	var bars *market.Aggregator
+	if mtf, ok := strat.(strategy.MultiTimeframe); ok && len(mtf.Timeframes()) > 0 {
+		var err error
+		if bars, err = market.NewAggregator(mtf.Timeframes(), historySize); err != nil {
+			return nil, err
+		}
+	}
+
+	var candleBuilder *market.CandleBuilder
+	candleStrat, isCandleStrategy := strat.(strategy.CandleStrategy)
+	if isCandleStrategy && candleStrat.CandleInterval() > 0 {
+		candleBuilder = market.NewCandleBuilder(candleStrat.CandleInterval())
+	}
+
+	if len(config.WarmUp) > 0 {
+		strategy.WarmUp(strat, config.WarmUp)
+		if bars != nil {
+			for i := range config.WarmUp {
+				bars.AddCandle(&config.WarmUp[i])
+			}
+		}
+	}
+
+	analyzer := strategy.Adapt(strat)
+	history := market.NewHistory(historySize)
+	result := &Result{
+		Strategy:       strat.Name(),
+		Start:          candles[0].OpenTime,
+		End:            candles[len(candles)-1].CloseTime,
+		Bars:           len(candles),
+		Currency:       quote,
+		InitialBalance: config.InitialBalance,
+		Equity:         make([]metrics.EquityPoint, 0, len(candles)),
+		Samples:        make([]metrics.Sample, 0, len(candles)),
+	}
+
+	for i := range candles {
+		candle := &candles[i]
+		now = candle.CloseTime
+
+		price, err := decimal.FromFloat(candle.Close)
+		if err != nil {
+			return nil, fmt.Errorf("candle at %s: invalid close: %w", candle.CloseTime.Format(time.RFC3339), err)
+		}
+		data := &market.Data{
+			Symbol:    config.Symbol,
+			Price:     price,
+			Volume:    candle.Volume,
+			Timestamp: candle.CloseTime,
+		}
+		history.Add(data)
+		if bars != nil {
+			bars.AddCandle(candle)
+		}
+
+		var signal strategy.Signal
+		if isCandleStrategy {
+			signal = analyzeCandles(candleStrat, candleBuilder, candle, p.GetPosition(asset).Sign() > 0)
+		} else {
+			var snapshot map[string][]market.Candle
+			if bars != nil {
+				snapshot = bars.Snapshot()
+			}
+			signal = analyzer.AnalyzeContext(&strategy.AnalysisContext{
+				Data:     data,
+				History:  history.Ticks(),
+				Bars:     snapshot,
+				Asset:    asset,
+				Position: p.GetPosition(asset).Float64(),
+				Cash:     p.GetBalance().Float64(),
+				Equity:   p.GetTotalValue(map[string]float64{asset: candle.Close}),
+				Now:      now,
+			})
+		}
+
+		execute(p, asset, quote, strat.Name(), signal, data.Price)
+		sample := metrics.NewSample(now, p.GetTotalValue(map[string]float64{asset: candle.Close}), p.GetBalance().Float64())
+		result.Samples = append(result.Samples, sample)
+		result.Equity = append(result.Equity, metrics.EquityPoint{Time: now, Value: sample.Equity})
+	}
+
+	result.Trades = p.GetHistory()
+	result.FinalEquity = result.Equity[len(result.Equity)-1].Value
+	result.NetProfit = result.FinalEquity - config.InitialBalance
+	result.ReturnPct = result.NetProfit / config.InitialBalance * 100
+	result.Report = metrics.Compute(result.Trades, result.Equity, map[string]float64{asset: candles[len(candles)-1].Close})
+
+	return result, nil
+}
+
+// analyzeCandles rebuilds the strategy's own candle interval from the
+// replayed candles and only analyzes the ones that have closed.
+func analyzeCandles(strat strategy.CandleStrategy, builder *market.CandleBuilder, candle *market.Candle, inPosition bool) strategy.Signal {
+	if tracker, ok := strat.(strategy.PositionTracker); ok {
+		tracker.SetInPosition(inPosition)
+	}
+
+	if builder == nil {
+		return strat.AnalyzeCandle(candle)
+	}
+
+	signal := strategy.Signal{Action: strategy.ActionHold, Symbol: candle.Symbol}
+	for _, closed := range builder.AddCandle(candle) {
+		signal = strat.AnalyzeCandle(&closed)
+	}
+	return signal
+}
+
+func execute(p *portfolio.Portfolio, asset, quote, name string, signal strategy.Signal, price decimal.Decimal) {
+	details := portfolio.TradeDetails{
+		Strategy:   name,
+		Reason:     signal.Reason,
+		Confidence: signal.Confidence,
+		Indicators: signal.Indicators,
+		OrderType:  string(signal.OrderType),
+		LimitPrice: signal.LimitPrice,
+		StopPrice:  signal.StopPrice,
+	}
+
+	// Signals the portfolio cannot book, such as amounts out of range or
+	// unaffordable buys, are skipped like the bot skips them.
+	amount, err := decimal.FromFloat(signal.Amount)
+	if err != nil || amount.Sign() <= 0 || price.Sign() <= 0 {
+		return
+	}
+
+	switch signal.Action {
+	case strategy.ActionBuy:
+		if p.GetBalance().Cmp(amount) < 0 {
+			return
+		}
+		if quantity, err := amount.DivTrunc(price); err == nil {
+			p.BuyPair(asset, quote, quantity, price, details)
+		}
+	case strategy.ActionSell:
+		if p.GetPosition(asset).Cmp(amount) >= 0 {
+			p.SellPair(asset, quote, amount, price, details)
+		}
+	}
+}
+
+func (r *Result) Print(w io.Writer) {
+	fmt.Fprintf(w, "\n=== Backtest: %s ===\n", r.Strategy)
+	fmt.Fprintf(w, "Period: %s - %s (%d bars)\n", r.Start.Format(time.RFC3339), r.End.Format(time.RFC3339), r.Bars)
+	fmt.Fprintf(w, "Initial Balance: %s\n", portfolio.FormatAmount(r.InitialBalance, r.Currency))
+	fmt.Fprintf(w, "Final Equity: %s\n", portfolio.FormatAmount(r.FinalEquity, r.Currency))
+	fmt.Fprintf(w, "Net Profit: %s (%.2f%%)\n", portfolio.FormatAmount(r.NetProfit, r.Currency), r.ReturnPct)
+	r.Report.Print(w, r.Currency)
+	fmt.Fprintln(w, "========================")
+}
@@ -1,31 +1,91 @@
 package bot
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"trading-bot/internal/api"
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/events"
 	"trading-bot/internal/exchange"
+	"trading-bot/internal/journal"
 	"trading-bot/internal/market"
+	"trading-bot/internal/metrics"
 	"trading-bot/internal/portfolio"
 	"trading-bot/internal/strategy"
 )
 
 type TradingBot struct {
-	strategy  strategy.Strategy
-	portfolio *portfolio.Portfolio
-	exchange  exchange.Exchange
-	config    *Config
-	running   bool
+	strategy   strategy.Strategy
+	analyzer   strategy.ContextStrategy
+	portfolio  *portfolio.Portfolio
+	exchange   exchange.Exchange
+	config     *Config
+	history    *market.History
+	bars       *market.Aggregator
+	openOrders []exchange.OrderResponse
+	// orderDetails keeps the signal details of open orders for the fills
+	// booked when they execute.
+	orderDetails map[int64]portfolio.TradeDetails
+	equity       *metrics.Curve
+	journal      *journal.Journal
+	bus          *events.Bus
+	logger       *slog.Logger
+	telemetry    *botTelemetry
+	apiServer    *api.Server
+	out          io.Writer
+	stop         chan struct{}
+	stopOnce     sync.Once
+
+	// turn holds a token while a tick, an API command or shutdown runs, so
+	// they never overlap. closed is closed once shutdown holds it for good.
+	turn   chan struct{}
+	closed chan struct{}
+	// status is published as each turn ends, for Status to read without one.
+	status atomic.Pointer[api.Status]
+
+	lastSample       time.Time
+	lastReconcile    time.Time
+	summarizedTrades int
+	halted           bool
+	paused           bool
+	peakEquity       float64
+	lastPrices       map[string]float64
+	lastSignal       strategy.Signal
+	lastSignalAt     time.Time
+	rates            map[string]float64
+	lastSimulatedID  int64
+	// seedBooks is set while live books still have to be started from the
+	// exchange account instead of the configured initial balances.
+	seedBooks bool
 }
 
+const (
+	historySize  = 500
+	contextFills = 50
+	// maxKlines is the most klines Binance returns per request.
+	maxKlines = 1000
+)
+
 func NewTradingBot(config *Config) (*TradingBot, error) {
-	var strat strategy.Strategy
-	switch config.Trading.Strategy {
-	case "rsi":
-		strat = strategy.NewRSIStrategy(14)
-	default:
-		strat = strategy.NewMovingAverageStrategy(20, 50)
+	strat, err := NewStrategy(config.Trading.Strategy, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create strategy: %w", err)
+	}
+	// The bot builds its candles from polled price ticks, which carry no
+	// volume, so a volume check could never pass.
+	if v, ok := strat.(strategy.VolumeStrategy); ok && v.NeedsVolume() {
+		return nil, fmt.Errorf("strategy %s confirms breakouts on volume, which polled price ticks do not carry; set trading.bollinger.volume_multiplier to 0", config.Trading.Strategy)
 	}
 
 	exch, err := exchange.NewBinanceClient(
@@ -37,100 +97,667 @@ func NewTradingBot(config *Config) (*TradingBot, error) {
 		return nil, fmt.Errorf("failed to create exchange client: %w", err)
 	}
 
-	return &TradingBot{
-		strategy:  strat,
-		portfolio: portfolio.NewPortfolio(config.Trading.InitialBalance),
-		exchange:  exch,
-		config:    config,
-		running:   false,
-	}, nil
+	var bars *market.Aggregator
+	if mtf, ok := strat.(strategy.MultiTimeframe); ok && len(mtf.Timeframes()) > 0 {
+		if bars, err = market.NewAggregator(mtf.Timeframes(), historySize); err != nil {
+			return nil, fmt.Errorf("failed to create bar aggregator: %w", err)
+		}
+	}
+
+	bot := &TradingBot{
+		strategy:     strat,
+		analyzer:     strategy.Adapt(strat),
+		portfolio:    newPortfolio(config, config.Balances()),
+		exchange:     exch,
+		config:       config,
+		history:      market.NewHistory(historySize),
+		bars:         bars,
+		equity:       metrics.NewCurve(config.Bot.Equity.MaxSamples),
+		rates:        make(map[string]float64),
+		orderDetails: make(map[int64]portfolio.TradeDetails),
+		bus:          events.NewBus(),
+		logger:       slog.Default().With("component", "bot", "symbol", config.Trading.Symbol, "strategy", strat.Name()),
+		stop:         make(chan struct{}),
+		out:          os.Stdout,
+		turn:         make(chan struct{}, 1),
+		closed:       make(chan struct{}),
+		seedBooks:    !config.Bot.DryRun,
+	}
+
+	if config.Bot.Prometheus.Enabled {
+		bot.telemetry = newBotTelemetry()
+		bot.bus.SubscribeFunc(1024, bot.telemetry.observeEvent)
+		exch.OnRequest = bot.telemetry.observeRequest
+	}
+
+	if config.Bot.API.Enabled {
+		bot.apiServer = api.NewServer(bot, config.Bot.API.Token, slog.Default().With("component", "api"))
+	}
+
+	if config.Bot.JournalFile != "" {
+		if err := bot.openJournal(); err != nil {
+			return nil, err
+		}
+	}
+	if config.Bot.Equity.File != "" {
+		bot.restoreEquity()
+	}
+
+	bot.publishStatus()
+	return bot, nil
+}
+
+func newPortfolio(config *Config, balances map[string]decimal.Decimal) *portfolio.Portfolio {
+	_, quote := config.Assets()
+	p := portfolio.NewPortfolioWithBalances(balances, quote)
+	p.SetCostMethod(config.CostMethod())
+	p.SetReportingCurrency(config.Reporting())
+	return p
+}
+
+// restoreEquity reloads the equity curve the bot streamed to its equity file
+// in earlier runs, so risk statistics and drawdown carry over restarts.
+func (bot *TradingBot) restoreEquity() {
+	samples, err := metrics.LoadSamplesCSV(bot.config.Bot.Equity.File)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		bot.logger.Warn("Failed to restore equity curve", "file", bot.config.Bot.Equity.File, "error", err)
+		return
+	}
+
+	for _, sample := range samples {
+		bot.equity.Record(sample)
+		bot.peakEquity = max(bot.peakEquity, sample.Equity)
+	}
+	if last, ok := bot.equity.Last(); ok {
+		bot.lastSample = last.Time
+	}
 }
 
-func (bot *TradingBot) Start() error {
-	bot.running = true
-	log.Printf("Trading bot started (DryRun: %v)", bot.config.Bot.DryRun)
+// openJournal opens the trade journal and, when it already has records,
+// rebuilds the portfolio, open orders and equity curve from them instead of
+// starting over from the initial balance.
+func (bot *TradingBot) openJournal() error {
+	j, records, err := journal.Open(bot.config.Bot.JournalFile)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	bot.journal = j
+
+	if n := j.Truncated(); n > 0 {
+		bot.logger.Warn("Discarded incomplete journal record", "bytes", n)
+	}
+
+	if len(records) == 0 {
+		// Live books get their genesis once they are seeded from the account.
+		if bot.seedBooks {
+			return nil
+		}
+		if err := j.AppendGenesis(bot.config.Balances(), bot.portfolio.CashAsset()); err != nil {
+			return fmt.Errorf("failed to start journal: %w", err)
+		}
+		return nil
+	}
+	bot.seedBooks = false
+
+	state, err := journal.Replay(records)
+	if err != nil {
+		return fmt.Errorf("failed to replay journal: %w", err)
+	}
+	if _, quote := bot.config.Assets(); state.Cash != quote {
+		bot.logger.Warn("Journal cash asset differs from quote asset", "cash", state.Cash, "quote", quote)
+	}
+
+	if bot.portfolio, err = state.Restore(bot.config.CostMethod()); err != nil {
+		return fmt.Errorf("failed to restore portfolio from journal: %w", err)
+	}
+	bot.portfolio.SetReportingCurrency(bot.config.Reporting())
+	bot.openOrders = state.OpenOrders
+	bot.summarizedTrades = len(state.Transactions)
+
+	bot.logger.Info("Restored portfolio from journal", "file", bot.config.Bot.JournalFile, "transactions", len(state.Transactions),
+		"open_orders", len(state.OpenOrders), "cash", bot.portfolio.GetBalance(), "cash_asset", bot.portfolio.CashAsset())
+	return nil
+}
+
+// Events is the bus the bot publishes its lifecycle and trading events on.
+// Subscribe before calling Start to see every event; the bus is closed once
+// the bot has stopped.
+func (bot *TradingBot) Events() *events.Bus {
+	return bot.bus
+}
+
+// Start runs the trading loop until ctx is cancelled or Stop is called, then
+// shuts down gracefully.
+func (bot *TradingBot) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-bot.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Ticks run on work rather than ctx, so shutdown can let a tick finish
+	// placing and booking an order instead of abandoning it halfway.
+	work, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+
+	bot.logger.Info("Trading bot started", "dry_run", bot.config.Bot.DryRun)
 
 	if !bot.config.Bot.DryRun {
-		if err := bot.exchange.TestConnection(); err != nil {
-			log.Printf("Failed to connect to exchange: %v", err)
+		if err := bot.exchange.TestConnection(ctx); err != nil {
+			bot.logger.Error("Failed to connect to exchange", "error", err)
+			bot.publishError("exchange", err)
+			bot.bus.Close()
 			return err
 		}
-		log.Println("Connected to exchange API")
+		bot.logger.Info("Connected to exchange API")
+
+		if bot.seedBooks {
+			if err := bot.seedFromAccount(ctx); err != nil {
+				bot.logger.Error("Failed to seed books from exchange account", "error", err)
+				bot.publishError("exchange", err)
+				bot.bus.Close()
+				return err
+			}
+		}
 	}
 
+	if bot.telemetry != nil {
+		address := bot.config.Bot.Prometheus.Address
+		if err := bot.telemetry.serve(address, func(err error) {
+			bot.logger.Error("Metrics server failed", "error", err)
+		}); err != nil {
+			bot.logger.Error("Failed to start metrics server", "error", err)
+			bot.bus.Close()
+			return err
+		}
+		bot.logger.Info("Serving Prometheus metrics", "address", address)
+	}
+
+	bot.prepareStrategy(ctx)
+	bot.reconcileIfDue(ctx)
+
+	// The API is served once startup no longer touches the bot's state
+	// outside a turn.
+	bot.publishStatus()
+	if bot.apiServer != nil {
+		address := bot.config.Bot.API.Address
+		if err := bot.apiServer.Serve(address); err != nil {
+			bot.logger.Error("Failed to start API server", "error", err)
+			if bot.telemetry != nil {
+				if err := bot.telemetry.close(context.Background()); err != nil {
+					bot.logger.Error("Failed to stop metrics server", "error", err)
+				}
+			}
+			bot.bus.Close()
+			return err
+		}
+		bot.logger.Info("Serving control API", "address", address)
+	}
+
+	bot.bus.Publish(events.Started{
+		Symbol:   bot.config.Trading.Symbol,
+		Strategy: bot.strategy.Name(),
+		DryRun:   bot.config.Bot.DryRun,
+	})
+
 	ticker := time.NewTicker(time.Duration(bot.config.Bot.IntervalSeconds) * time.Second)
 	defer ticker.Stop()
 
-	for bot.running {
+	// A tick that overruns the interval, or finds an API command running,
+	// makes the next one skip rather than wait.
+	for {
 		select {
+		case <-ctx.Done():
+			return bot.shutdown(cancelWork)
 		case <-ticker.C:
-			if err := bot.processTick(); err != nil {
-				log.Printf("Error processing tick: %v", err)
+			select {
+			case bot.turn <- struct{}{}:
+			default:
+				continue
 			}
+			go func() {
+				defer bot.release()
+				bot.tick(work)
+			}()
 		}
 	}
-	return nil
 }
 
-func (bot *TradingBot) processTick() error {
+func (bot *TradingBot) tick(ctx context.Context) {
+	if err := bot.processTick(ctx); err != nil {
+		bot.logger.Error("Error processing tick", "error", err)
+		bot.publishError("bot", err)
+	}
+	bot.saveStrategyState()
+	bot.reconcileIfDue(ctx)
+}
+
+// prepareStrategy restores the strategy's last snapshot so it can signal
+// immediately after a restart, falling back to warming it up from
+// historical klines when there is no snapshot or it is too old.
+func (bot *TradingBot) prepareStrategy(ctx context.Context) {
+	bot.seedBars(ctx)
+
+	if stateFile := bot.config.Bot.StateFile; stateFile != "" {
+		if _, err := os.Stat(stateFile); err == nil {
+			maxAge := time.Duration(bot.config.Bot.StateMaxAge) * time.Second
+			savedAt, err := strategy.LoadState(bot.strategy, stateFile, maxAge)
+			if err == nil {
+				bot.logger.Info("Restored strategy state", "saved_at", savedAt.Format(time.RFC3339))
+				return
+			}
+			bot.logger.Warn("Failed to restore strategy state", "error", err)
+		}
+	}
+
+	if !bot.config.Bot.WarmUp.Enabled {
+		return
+	}
+
+	candles, interval, err := bot.warmUpCandles(ctx)
+	if err != nil {
+		bot.logger.Warn("Failed to fetch warm up klines", "error", err)
+		return
+	}
+
+	strategy.WarmUp(bot.strategy, candles)
+	bot.logger.Info("Warmed up strategy", "candles", len(candles), "interval", interval)
+}
+
+// warmUpCandles fetches history at the interval the strategy consumes: its
+// candle interval, or the tick interval for strategies fed raw ticks, so
+// warm-up and live data form one series. Intervals Binance has no klines for,
+// such as 10s, are resampled from shorter klines, which caps how far back a
+// single request reaches.
+func (bot *TradingBot) warmUpCandles(ctx context.Context) ([]market.Candle, time.Duration, error) {
+	interval := time.Duration(bot.config.Bot.IntervalSeconds) * time.Second
+	if candles, ok := bot.strategy.(strategy.CandleStrategy); ok && candles.CandleInterval() > 0 {
+		interval = candles.CandleInterval()
+	}
+
+	source, step, ok := market.KlineSource(interval)
+	if !ok {
+		return nil, interval, fmt.Errorf("no klines to build %s bars from", interval)
+	}
+
+	ratio := int(interval / step)
+	limit := min(bot.config.Bot.WarmUp.Limit*ratio, maxKlines)
+	candles, err := bot.exchange.GetKlines(ctx, bot.config.Trading.Symbol, source, limit)
+	if err != nil {
+		return nil, interval, err
+	}
+	if ratio > 1 {
+		candles = market.Resample(candles, interval)
+	}
+	return candles, interval, nil
+}
+
+// seedBars preloads closed bars for every timeframe the strategy declared so
+// higher timeframes are usable from the first tick.
+func (bot *TradingBot) seedBars(ctx context.Context) {
+	if bot.bars == nil || !bot.config.Bot.WarmUp.Enabled {
+		return
+	}
+
+	for _, interval := range bot.bars.Intervals() {
+		candles, err := bot.exchange.GetKlines(ctx, bot.config.Trading.Symbol, interval, bot.config.Bot.WarmUp.Limit)
+		if err != nil {
+			bot.logger.Warn("Failed to fetch klines", "interval", interval, "error", err)
+			continue
+		}
+		if err := bot.bars.Seed(interval, candles, time.Now()); err != nil {
+			bot.logger.Warn("Failed to seed bars", "interval", interval, "error", err)
+		}
+	}
+}
+
+func (bot *TradingBot) saveStrategyState() {
+	if bot.config.Bot.StateFile == "" {
+		return
+	}
+	if _, ok := bot.strategy.(strategy.Stateful); !ok {
+		return
+	}
+
+	if err := strategy.SaveState(bot.strategy, bot.config.Bot.StateFile); err != nil {
+		bot.logger.Error("Failed to save strategy state", "error", err)
+	}
+}
+
+func (bot *TradingBot) processTick(ctx context.Context) error {
 	var marketData *market.Data
 	var err error
 
 	if bot.config.Bot.DryRun {
-		marketData, err = market.FetchMockData(bot.config.Trading.Symbol)
+		marketData, err = market.FetchMockData(ctx, bot.config.Trading.Symbol)
 	} else {
-		marketData, err = bot.exchange.GetMarketData(bot.config.Trading.Symbol)
+		marketData, err = bot.exchange.GetMarketData(ctx, bot.config.Trading.Symbol)
 	}
 
 	if err != nil {
 		return fmt.Errorf("error fetching market data: %w", err)
 	}
 
-	signal := bot.strategy.Analyze(marketData)
+	bot.history.Add(marketData)
+	bot.bus.Publish(events.TickReceived{Data: *marketData})
+	if bot.bars != nil {
+		bot.bars.AddTick(marketData)
+	}
+	bot.refreshOpenOrders(ctx)
+
+	currentPrices := bot.valuationPrices(ctx, marketData)
+	bot.lastPrices = currentPrices
+	signal := bot.analyzer.AnalyzeContext(bot.analysisContext(marketData, currentPrices))
+	bot.lastSignal, bot.lastSignalAt = signal, time.Now()
+
+	if signal.Action != strategy.ActionHold {
+		bot.logSignal(signal)
+		bot.bus.Publish(events.SignalGenerated{Strategy: bot.strategy.Name(), Signal: signal})
+	}
+
+	if bot.halted && signal.Action != strategy.ActionHold {
+		bot.logger.Warn("Trading halted, ignoring signal", "action", signal.Action)
+		bot.bus.Publish(events.RiskLimitHit{Limit: "halted", Action: signal.Action, Detail: "trading halted by reconciliation"})
+		signal.Action = strategy.ActionHold
+	}
+	if bot.paused && signal.Action != strategy.ActionHold {
+		bot.logger.Info("Trading paused, ignoring signal", "action", signal.Action)
+		bot.bus.Publish(events.RiskLimitHit{Limit: "paused", Action: signal.Action, Detail: "trading paused through the API"})
+		signal.Action = strategy.ActionHold
+	}
+
+	details := bot.tradeDetails(signal)
+	// Buys are sized at the limit price, or the tick price for market orders;
+	// fills are booked at whatever price they execute at.
+	orderType, limitPrice, sizingPrice := exchange.TypeMarket, decimal.Zero, marketData.Price
+	if signal.OrderType == strategy.OrderLimit && signal.LimitPrice > 0 {
+		limit, err := decimal.FromFloat(signal.LimitPrice)
+		orderType, limitPrice, sizingPrice = exchange.TypeLimit, limit, limit
+		// A limit price below the smallest Decimal would size a buy by zero.
+		if err == nil && limit.Sign() <= 0 {
+			err = fmt.Errorf("limit price %g rounds to zero", signal.LimitPrice)
+		}
+		if err != nil && signal.Action != strategy.ActionHold {
+			bot.rejectSignal(signal, err)
+			signal.Action = strategy.ActionHold
+		}
+	}
+
+	asset, quote := bot.config.Assets()
 
 	switch signal.Action {
 	case strategy.ActionBuy:
-		if bot.portfolio.GetBalance() >= signal.Amount {
-			if bot.config.Bot.DryRun {
-				bot.portfolio.Buy(signal.Symbol, signal.Amount, marketData.Price)
-			} else {
-				quantity := signal.Amount / marketData.Price
-				_, err := bot.exchange.PlaceOrder(bot.config.Trading.Symbol, exchange.SideBuy, exchange.TypeMarket, quantity, 0)
-				if err != nil {
-					log.Printf("Failed to place BUY order: %v", err)
-				} else {
-					bot.portfolio.Buy(signal.Symbol, signal.Amount, marketData.Price)
-				}
-			}
+		amount, err := decimal.FromFloat(signal.Amount)
+		if err != nil {
+			bot.rejectSignal(signal, err)
+			break
+		}
+		if available := bot.portfolio.GetPosition(quote); available.Cmp(amount) < 0 {
+			bot.bus.Publish(events.RiskLimitHit{
+				Limit:  "insufficient_balance",
+				Action: signal.Action,
+				Detail: fmt.Sprintf("need %s %s, have %s", amount, quote, available),
+			})
+			break
 		}
+		quantity, err := amount.DivTrunc(sizingPrice)
+		if err != nil {
+			bot.rejectSignal(signal, err)
+			break
+		}
+		bot.execute(ctx, exchange.SideBuy, quantity, orderType, limitPrice, marketData.Price, details)
 	case strategy.ActionSell:
-		position := bot.portfolio.GetPosition(signal.Symbol)
-		if position >= signal.Amount {
-			if bot.config.Bot.DryRun {
-				bot.portfolio.Sell(signal.Symbol, signal.Amount, marketData.Price)
-			} else {
-				_, err := bot.exchange.PlaceOrder(bot.config.Trading.Symbol, exchange.SideSell, exchange.TypeMarket, signal.Amount, 0)
-				if err != nil {
-					log.Printf("Failed to place SELL order: %v", err)
-				} else {
-					bot.portfolio.Sell(signal.Symbol, signal.Amount, marketData.Price)
-				}
-			}
+		quantity, err := decimal.FromFloat(signal.Amount)
+		if err != nil {
+			bot.rejectSignal(signal, err)
+			break
+		}
+		if quantity.Sign() > 0 && bot.portfolio.GetPosition(asset).Cmp(quantity) >= 0 {
+			bot.execute(ctx, exchange.SideSell, quantity, orderType, limitPrice, marketData.Price, details)
 		}
 	}
 
-	currentPrices := map[string]float64{
-		signal.Symbol: marketData.Price,
-	}
+	bot.recordEquity(currentPrices)
+	bot.observeEquity(currentPrices)
 
-	if len(bot.portfolio.GetHistory())%10 == 0 {
-		bot.portfolio.PrintSummary(currentPrices)
+	// Summarize every 10 trades, once per multiple rather than on every tick
+	// while the count sits at one.
+	if trades := bot.portfolio.TransactionCount(); trades != bot.summarizedTrades && trades%10 == 0 {
+		bot.summarizedTrades = trades
+		bot.printSummary(currentPrices)
 	}
 
 	return nil
 }
 
+// valuationPrices prices every held asset in the reporting currency. The
+// traded asset's price is converted through its quote asset when that is not
+// the reporting currency; other assets are priced from their own ticker
+// against the reporting currency, keeping the last known rate when a fetch
+// fails.
+func (bot *TradingBot) valuationPrices(ctx context.Context, marketData *market.Data) map[string]float64 {
+	asset, quote := bot.config.Assets()
+	reporting := bot.portfolio.ReportingCurrency()
+	prices := map[string]float64{reporting: 1}
+
+	needed := []string{quote}
+	for held := range bot.portfolio.GetBalances() {
+		if held != asset && held != quote {
+			needed = append(needed, held)
+		}
+	}
+	for _, held := range needed {
+		if held == reporting {
+			continue
+		}
+		if rate, err := bot.fetchRate(ctx, held+reporting); err == nil {
+			bot.rates[held] = rate
+		} else if _, known := bot.rates[held]; !known {
+			bot.logger.Warn("Failed to price asset", "asset", held, "reporting_currency", reporting, "error", err)
+			continue
+		}
+		prices[held] = bot.rates[held]
+	}
+
+	if rate, ok := prices[quote]; ok {
+		prices[asset] = marketData.Price.Float64() * rate
+	}
+	return prices
+}
+
+func (bot *TradingBot) fetchRate(ctx context.Context, symbol string) (float64, error) {
+	var data *market.Data
+	var err error
+	if bot.config.Bot.DryRun {
+		data, err = market.FetchMockData(ctx, symbol)
+	} else {
+		data, err = bot.exchange.GetMarketData(ctx, symbol)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return data.Price.Float64(), nil
+}
+
+// recordEquity samples the account at most once per configured cadence and
+// appends the sample to the equity file.
+func (bot *TradingBot) recordEquity(currentPrices map[string]float64) {
+	now := time.Now()
+	cadence := time.Duration(bot.config.Bot.Equity.SampleSeconds) * time.Second
+	if !bot.lastSample.IsZero() && now.Sub(bot.lastSample) < cadence {
+		return
+	}
+
+	// Equity without a rate for every held asset would drop them to zero
+	// and show up as a crash in the curve and its drawdown.
+	snapshot := bot.portfolio.Snapshot(currentPrices)
+	if unpriced := snapshot.Unpriced(); len(unpriced) > 0 {
+		bot.logger.Warn("Skipping equity sample, assets have no price yet", "assets", unpriced)
+		return
+	}
+	bot.lastSample = now
+
+	sample := metrics.NewSample(now, snapshot.Equity, reportingCash(snapshot, currentPrices))
+	bot.equity.Record(sample)
+
+	if file := bot.config.Bot.Equity.File; file != "" {
+		if err := metrics.AppendSampleCSV(file, sample); err != nil {
+			bot.logger.Error("Failed to record equity", "error", err)
+		}
+	}
+}
+
+// reportingCash values the cash balance in the reporting currency.
+func reportingCash(snapshot portfolio.Snapshot, currentPrices map[string]float64) float64 {
+	cash := snapshot.Balance.Float64()
+	if snapshot.Cash != snapshot.ReportingCurrency {
+		cash *= currentPrices[snapshot.Cash]
+	}
+	return cash
+}
+
+func (bot *TradingBot) EquitySamples() []metrics.Sample {
+	return bot.equity.Samples()
+}
+
+func (bot *TradingBot) printSummary(currentPrices map[string]float64) {
+	bot.portfolio.PrintSummary(bot.out, currentPrices)
+
+	fmt.Fprintln(bot.out, "=== Performance ===")
+	metrics.Compute(bot.portfolio.GetHistory(), bot.equity.Points(), currentPrices).Print(bot.out, bot.portfolio.ReportingCurrency())
+	fmt.Fprintln(bot.out, "========================")
+}
+
+// SetOutput redirects the periodic portfolio and performance summaries,
+// which go to stdout by default. Call it before Start.
+func (bot *TradingBot) SetOutput(w io.Writer) {
+	bot.out = w
+}
+
+func (bot *TradingBot) analysisContext(marketData *market.Data, currentPrices map[string]float64) *strategy.AnalysisContext {
+	asset, quote := bot.config.Assets()
+
+	snapshot := bot.portfolio.Snapshot(currentPrices)
+	transactions := bot.portfolio.GetRecentTransactions(contextFills)
+	fills := make([]strategy.Fill, 0, len(transactions))
+	for _, t := range transactions {
+		if t.Type != "BUY" && t.Type != "SELL" {
+			continue
+		}
+		fills = append(fills, strategy.Fill{
+			Timestamp: t.Timestamp,
+			Side:      strategy.Action(t.Type),
+			Symbol:    t.Symbol,
+			Quantity:  t.Amount.Float64(),
+			Price:     t.Price.Float64(),
+		})
+	}
+
+	openOrders := make([]strategy.OpenOrder, 0, len(bot.openOrders))
+	for _, order := range bot.openOrders {
+		openOrders = append(openOrders, strategy.OpenOrder{
+			ID:        order.OrderID,
+			Symbol:    order.Symbol,
+			Side:      strategy.Action(order.Side),
+			Type:      strategy.OrderType(order.Type),
+			Quantity:  order.Quantity.Float64(),
+			Price:     order.Price.Float64(),
+			CreatedAt: time.UnixMilli(max(order.Time, order.TransactTime)),
+		})
+	}
+
+	var bars map[string][]market.Candle
+	if bot.bars != nil {
+		bars = bot.bars.Snapshot()
+	}
+
+	return &strategy.AnalysisContext{
+		Data:       marketData,
+		History:    bot.history.Ticks(),
+		Bars:       bars,
+		Asset:      asset,
+		Position:   snapshot.Positions[asset].Quantity.Float64(),
+		Cash:       snapshot.Balances[quote].Float64(),
+		Equity:     snapshot.Equity,
+		OpenOrders: openOrders,
+		Fills:      fills,
+		Now:        time.Now(),
+	}
+}
+
+func (bot *TradingBot) journalTransaction(t portfolio.Transaction) {
+	if bot.journal == nil {
+		return
+	}
+	if err := bot.journal.AppendTransaction(t); err != nil {
+		bot.logger.Error("Failed to journal transaction", "error", err)
+	}
+}
+
+func (bot *TradingBot) journalOrder(order exchange.OrderResponse) {
+	if bot.journal == nil {
+		return
+	}
+	if err := bot.journal.AppendOrder(order); err != nil {
+		bot.logger.Error("Failed to journal order", "order_id", order.OrderID, "error", err)
+	}
+}
+
+func (bot *TradingBot) publishError(component string, err error) {
+	bot.bus.Publish(events.Error{Component: component, Err: err})
+}
+
+func (bot *TradingBot) logSignal(signal strategy.Signal) {
+	attrs := []any{"action", signal.Action, "confidence", signal.Confidence}
+	if signal.Reason != "" {
+		attrs = append(attrs, "reason", signal.Reason)
+	}
+	if len(signal.Sources) > 0 {
+		attrs = append(attrs, "sources", strings.Join(signal.Sources, ","))
+	}
+	if len(signal.Indicators) > 0 {
+		keys := make([]string, 0, len(signal.Indicators))
+		for key := range signal.Indicators {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		indicators := make([]any, len(keys))
+		for i, key := range keys {
+			indicators[i] = slog.Float64(key, signal.Indicators[key])
+		}
+		attrs = append(attrs, slog.Group("indicators", indicators...))
+	}
+	if signal.OrderType != "" {
+		attrs = append(attrs, "order_type", signal.OrderType, "limit_price", signal.LimitPrice)
+	}
+	if signal.StopPrice > 0 {
+		attrs = append(attrs, "stop_price", signal.StopPrice)
+	}
+	bot.logger.Info("Signal", attrs...)
+}
+
+func (bot *TradingBot) tradeDetails(signal strategy.Signal) portfolio.TradeDetails {
+	return portfolio.TradeDetails{
+		Strategy:   bot.strategy.Name(),
+		Reason:     signal.Reason,
+		Confidence: signal.Confidence,
+		Indicators: signal.Indicators,
+		OrderType:  string(signal.OrderType),
+		LimitPrice: signal.LimitPrice,
+		StopPrice:  signal.StopPrice,
+	}
+}
+
+// Stop asks a running Start to shut down. It is safe to call from any
+// goroutine, more than once, and before Start.
 func (bot *TradingBot) Stop() {
-	bot.running = false
-	log.Println("Trading bot stopped")
+	bot.stopOnce.Do(func() { close(bot.stop) })
 }
@@ -4,6 +4,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/market"
+	"trading-bot/internal/portfolio"
+	"trading-bot/internal/reconcile"
+	"trading-bot/internal/script"
 )
 
 type Config struct {
@@ -14,20 +22,113 @@ type Config struct {
 	} `json:"binance"`
 
 	Trading struct {
-		Symbol         string  `json:"symbol"`
-		InitialBalance float64 `json:"initial_balance"`
-		Strategy       string  `json:"strategy"`
-		MaxRisk        float64 `json:"max_risk"`
-		StopLoss       float64 `json:"stop_loss"`
+		Symbol            string             `json:"symbol"`
+		InitialBalance    float64            `json:"initial_balance"`
+		InitialBalances   map[string]float64 `json:"initial_balances,omitempty"`
+		ReportingCurrency string             `json:"reporting_currency"`
+		Strategy          string             `json:"strategy"`
+		MaxRisk           float64            `json:"max_risk"`
+		StopLoss          float64            `json:"stop_loss"`
+		CostBasis         string             `json:"cost_basis"`
+
+		MovingAverage struct {
+			ShortPeriod      int    `json:"short_period"`
+			LongPeriod       int    `json:"long_period"`
+			Type             string `json:"type"`
+			ConfirmationBars int    `json:"confirmation_bars"`
+			PositionAware    bool   `json:"position_aware"`
+		} `json:"moving_average"`
+
+		MACD struct {
+			FastPeriod         int  `json:"fast_period"`
+			SlowPeriod         int  `json:"slow_period"`
+			SignalPeriod       int  `json:"signal_period"`
+			HistogramFilter    bool `json:"histogram_filter"`
+			DivergenceFilter   bool `json:"divergence_filter"`
+			DivergenceLookback int  `json:"divergence_lookback"`
+		} `json:"macd"`
+
+		Bollinger struct {
+			Period           int     `json:"period"`
+			StdDev           float64 `json:"std_dev"`
+			Mode             string  `json:"mode"`
+			Interval         string  `json:"interval"`
+			VolumePeriod     int     `json:"volume_period"`
+			VolumeMultiplier float64 `json:"volume_multiplier"`
+		} `json:"bollinger"`
+
+		MultiTimeframe struct {
+			EntryInterval string `json:"entry_interval"`
+			TrendInterval string `json:"trend_interval"`
+			FastPeriod    int    `json:"fast_period"`
+			SlowPeriod    int    `json:"slow_period"`
+			TrendPeriod   int    `json:"trend_period"`
+		} `json:"multi_timeframe"`
+
+		Script struct {
+			Entry    string `json:"entry"`
+			Exit     string `json:"exit"`
+			Interval string `json:"interval"`
+		} `json:"script"`
+
+		Composite struct {
+			Rule       string                 `json:"rule"`
+			Threshold  float64                `json:"threshold"`
+			Strategies []CompositeChildConfig `json:"strategies"`
+		} `json:"composite"`
 	} `json:"trading"`
 
 	Bot struct {
 		IntervalSeconds int    `json:"interval_seconds"`
 		DryRun          bool   `json:"dry_run"`
 		LogLevel        string `json:"log_level"`
+		LogFormat       string `json:"log_format"`
+		StateFile       string `json:"state_file"`
+		StateMaxAge     int    `json:"state_max_age_seconds"`
+		JournalFile     string `json:"journal_file"`
+
+		WarmUp struct {
+			Enabled bool `json:"enabled"`
+			Limit   int  `json:"limit"`
+		} `json:"warm_up"`
+
+		Equity struct {
+			SampleSeconds int    `json:"sample_seconds"`
+			MaxSamples    int    `json:"max_samples"`
+			File          string `json:"file"`
+		} `json:"equity"`
+
+		Reconcile struct {
+			Enabled         bool    `json:"enabled"`
+			IntervalSeconds int     `json:"interval_seconds"`
+			Policy          string  `json:"policy"`
+			Tolerance       float64 `json:"tolerance"`
+		} `json:"reconcile"`
+
+		Shutdown struct {
+			TimeoutSeconds   int  `json:"timeout_seconds"`
+			CancelOpenOrders bool `json:"cancel_open_orders"`
+		} `json:"shutdown"`
+
+		Prometheus struct {
+			Enabled bool   `json:"enabled"`
+			Address string `json:"address"`
+		} `json:"prometheus"`
+
+		API struct {
+			Enabled bool   `json:"enabled"`
+			Address string `json:"address"`
+			Token   string `json:"token"`
+		} `json:"api"`
 	} `json:"bot"`
 }
 
+type CompositeChildConfig struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Role   string  `json:"role"`
+}
+
 func LoadConfig(filename string) (*Config, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -51,13 +152,68 @@ func CreateDefaultConfig(filename string) error {
 
 	defaultConfig.Trading.Symbol = "BTCUSDT"
 	defaultConfig.Trading.InitialBalance = 10000.0
+	defaultConfig.Trading.ReportingCurrency = "USDT"
 	defaultConfig.Trading.Strategy = "moving_average"
 	defaultConfig.Trading.MaxRisk = 0.02
 	defaultConfig.Trading.StopLoss = 0.05
+	defaultConfig.Trading.CostBasis = "fifo"
+
+	defaultConfig.Trading.MovingAverage.ShortPeriod = 20
+	defaultConfig.Trading.MovingAverage.LongPeriod = 50
+	defaultConfig.Trading.MovingAverage.Type = "sma"
+	defaultConfig.Trading.MovingAverage.ConfirmationBars = 0
+	defaultConfig.Trading.MovingAverage.PositionAware = true
+
+	defaultConfig.Trading.MACD.FastPeriod = 12
+	defaultConfig.Trading.MACD.SlowPeriod = 26
+	defaultConfig.Trading.MACD.SignalPeriod = 9
+	defaultConfig.Trading.MACD.DivergenceLookback = 20
+
+	defaultConfig.Trading.Bollinger.Period = 20
+	defaultConfig.Trading.Bollinger.StdDev = 2.0
+	defaultConfig.Trading.Bollinger.Mode = "mean_reversion"
+	defaultConfig.Trading.Bollinger.Interval = "1m"
+	defaultConfig.Trading.Bollinger.VolumePeriod = 20
+	defaultConfig.Trading.Bollinger.VolumeMultiplier = 1.5
+
+	defaultConfig.Trading.MultiTimeframe.EntryInterval = "1m"
+	defaultConfig.Trading.MultiTimeframe.TrendInterval = "1h"
+	defaultConfig.Trading.MultiTimeframe.FastPeriod = 9
+	defaultConfig.Trading.MultiTimeframe.SlowPeriod = 21
+	defaultConfig.Trading.MultiTimeframe.TrendPeriod = 50
+
+	defaultConfig.Trading.Script.Entry = "rsi(14) < 30 and close > ema(200)"
+	defaultConfig.Trading.Script.Exit = "rsi(14) > 70 or close < ema(200)"
+	defaultConfig.Trading.Script.Interval = "1m"
+
+	defaultConfig.Trading.Composite.Rule = "trend_filter"
+	defaultConfig.Trading.Composite.Strategies = []CompositeChildConfig{
+		{Name: "moving_average", Weight: 1.0, Role: "filter"},
+		{Name: "rsi", Weight: 1.0, Role: "trigger"},
+	}
 
 	defaultConfig.Bot.IntervalSeconds = 10
 	defaultConfig.Bot.DryRun = true
 	defaultConfig.Bot.LogLevel = "info"
+	defaultConfig.Bot.LogFormat = "text"
+	defaultConfig.Bot.StateFile = "data/strategy_state.json"
+	defaultConfig.Bot.StateMaxAge = 900
+	defaultConfig.Bot.JournalFile = "data/journal.log"
+	defaultConfig.Bot.WarmUp.Enabled = true
+	defaultConfig.Bot.WarmUp.Limit = 200
+	defaultConfig.Bot.Equity.SampleSeconds = 60
+	defaultConfig.Bot.Equity.MaxSamples = 10000
+	defaultConfig.Bot.Equity.File = "data/equity.csv"
+	defaultConfig.Bot.Reconcile.Enabled = true
+	defaultConfig.Bot.Reconcile.IntervalSeconds = 60
+	defaultConfig.Bot.Reconcile.Policy = "report"
+	defaultConfig.Bot.Reconcile.Tolerance = 0.001
+	defaultConfig.Bot.Shutdown.TimeoutSeconds = 10
+	defaultConfig.Bot.Shutdown.CancelOpenOrders = false
+	defaultConfig.Bot.Prometheus.Enabled = false
+	defaultConfig.Bot.Prometheus.Address = "127.0.0.1:9321"
+	defaultConfig.Bot.API.Enabled = false
+	defaultConfig.Bot.API.Address = "127.0.0.1:8080"
 
 	data, err := json.MarshalIndent(defaultConfig, "", "  ")
 	if err != nil {
@@ -84,14 +240,255 @@ func (c *Config) Validate() error {
 	if c.Trading.InitialBalance <= 0 {
 		return fmt.Errorf("initial balance must be positive")
 	}
+	if _, err := decimal.FromFloat(c.Trading.InitialBalance); err != nil {
+		return fmt.Errorf("initial balance: %w", err)
+	}
+
+	for asset, amount := range c.Trading.InitialBalances {
+		if asset == "" || amount < 0 {
+			return fmt.Errorf("initial balances need an asset name and a non-negative amount")
+		}
+		if _, err := decimal.FromFloat(amount); err != nil {
+			return fmt.Errorf("initial balance of %s: %w", asset, err)
+		}
+	}
 
 	if c.Trading.MaxRisk <= 0 || c.Trading.MaxRisk >= 1 {
 		return fmt.Errorf("max risk must be between 0 and 1")
 	}
 
+	if _, err := portfolio.ParseCostMethod(c.Trading.CostBasis); err != nil {
+		return fmt.Errorf("cost basis must be fifo, lifo or average")
+	}
+
+	if !slices.Contains(strategyNames, c.Trading.Strategy) {
+		return fmt.Errorf("strategy must be one of %s", strings.Join(strategyNames, ", "))
+	}
+
+	ma := c.Trading.MovingAverage
+	if ma.ShortPeriod < 0 || ma.LongPeriod < 0 {
+		return fmt.Errorf("moving average periods must not be negative")
+	}
+	if ma.ShortPeriod > 0 && ma.LongPeriod > 0 && ma.ShortPeriod >= ma.LongPeriod {
+		return fmt.Errorf("moving average short period must be less than long period")
+	}
+	if ma.Type != "" && ma.Type != "sma" && ma.Type != "ema" {
+		return fmt.Errorf("moving average type must be sma or ema")
+	}
+	if ma.ConfirmationBars < 0 {
+		return fmt.Errorf("moving average confirmation bars must not be negative")
+	}
+
+	macd := c.Trading.MACD
+	if macd.FastPeriod < 0 || macd.SlowPeriod < 0 || macd.SignalPeriod < 0 || macd.DivergenceLookback < 0 {
+		return fmt.Errorf("macd periods must not be negative")
+	}
+	if macd.FastPeriod > 0 && macd.SlowPeriod > 0 && macd.FastPeriod >= macd.SlowPeriod {
+		return fmt.Errorf("macd fast period must be less than slow period")
+	}
+
+	bb := c.Trading.Bollinger
+	if bb.Period < 0 || bb.StdDev < 0 || bb.VolumePeriod < 0 {
+		return fmt.Errorf("bollinger period and std dev must not be negative")
+	}
+	if bb.Mode != "" && bb.Mode != "mean_reversion" && bb.Mode != "breakout" {
+		return fmt.Errorf("bollinger mode must be mean_reversion or breakout")
+	}
+	if bb.Interval != "" {
+		if _, err := market.ParseInterval(bb.Interval); err != nil {
+			return fmt.Errorf("bollinger interval: %w", err)
+		}
+	}
+
+	mtf := c.Trading.MultiTimeframe
+	for _, interval := range []string{mtf.EntryInterval, mtf.TrendInterval} {
+		if interval == "" {
+			continue
+		}
+		if _, err := market.ParseInterval(interval); err != nil {
+			return fmt.Errorf("multi timeframe interval: %w", err)
+		}
+	}
+	if mtf.FastPeriod > 0 && mtf.SlowPeriod > 0 && mtf.FastPeriod >= mtf.SlowPeriod {
+		return fmt.Errorf("multi timeframe fast period must be less than slow period")
+	}
+
+	if c.usesStrategy("script") {
+		if _, err := script.Compile(c.Trading.Script.Entry); err != nil {
+			return fmt.Errorf("script entry rule: %w", err)
+		}
+		if _, err := script.Compile(c.Trading.Script.Exit); err != nil {
+			return fmt.Errorf("script exit rule: %w", err)
+		}
+		if c.Trading.Script.Interval != "" {
+			if _, err := market.ParseInterval(c.Trading.Script.Interval); err != nil {
+				return fmt.Errorf("script interval: %w", err)
+			}
+		}
+	}
+
+	if c.Trading.Strategy == "composite" {
+		if err := c.validateComposite(); err != nil {
+			return err
+		}
+	}
+
 	if c.Bot.IntervalSeconds <= 0 {
 		return fmt.Errorf("interval seconds must be positive")
 	}
 
+	if c.Bot.StateMaxAge < 0 {
+		return fmt.Errorf("state max age seconds must not be negative")
+	}
+
+	if c.Bot.WarmUp.Enabled {
+		if c.Bot.WarmUp.Limit <= 0 || c.Bot.WarmUp.Limit > 1000 {
+			return fmt.Errorf("warm up limit must be between 1 and 1000")
+		}
+	}
+
+	if c.Bot.Equity.SampleSeconds < 0 || c.Bot.Equity.MaxSamples < 0 {
+		return fmt.Errorf("equity sample seconds and max samples must not be negative")
+	}
+
+	if c.Bot.Reconcile.Enabled {
+		if c.Bot.Reconcile.IntervalSeconds <= 0 {
+			return fmt.Errorf("reconcile interval seconds must be positive")
+		}
+		if _, err := reconcile.ParsePolicy(c.Bot.Reconcile.Policy); err != nil {
+			return fmt.Errorf("reconcile policy must be report, adopt or halt")
+		}
+		if c.Bot.Reconcile.Tolerance < 0 || c.Bot.Reconcile.Tolerance >= 1 {
+			return fmt.Errorf("reconcile tolerance must be between 0 and 1")
+		}
+	}
+
+	if c.Bot.Shutdown.TimeoutSeconds < 0 {
+		return fmt.Errorf("shutdown timeout seconds must not be negative")
+	}
+
+	if c.Bot.Prometheus.Enabled && c.Bot.Prometheus.Address == "" {
+		return fmt.Errorf("prometheus address must be set when prometheus is enabled")
+	}
+
+	if c.Bot.API.Enabled {
+		if c.Bot.API.Address == "" {
+			return fmt.Errorf("api address must be set when the api is enabled")
+		}
+		if len(c.Bot.API.Token) < 16 {
+			return fmt.Errorf("api token must be at least 16 characters")
+		}
+	}
+
+	if _, err := parseLogLevel(c.Bot.LogLevel); err != nil {
+		return err
+	}
+	if c.Bot.LogFormat != "" && c.Bot.LogFormat != "text" && c.Bot.LogFormat != "json" {
+		return fmt.Errorf("log format must be text or json")
+	}
+
+	return nil
+}
+
+func (c *Config) usesStrategy(name string) bool {
+	if c.Trading.Strategy == name {
+		return true
+	}
+	if c.Trading.Strategy == "composite" {
+		for _, child := range c.Trading.Composite.Strategies {
+			if child.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *Config) validateComposite() error {
+	composite := c.Trading.Composite
+
+	switch composite.Rule {
+	case "", "all", "any", "majority", "weighted", "trend_filter":
+	default:
+		return fmt.Errorf("composite rule must be all, any, majority, weighted or trend_filter")
+	}
+
+	if len(composite.Strategies) == 0 {
+		return fmt.Errorf("composite strategy requires at least one child strategy")
+	}
+
+	if composite.Threshold < 0 || composite.Threshold > 1 {
+		return fmt.Errorf("composite threshold must be between 0 and 1")
+	}
+
+	filters := 0
+	for _, child := range composite.Strategies {
+		if child.Name == "composite" {
+			return fmt.Errorf("composite strategy cannot contain another composite strategy")
+		}
+		if !slices.Contains(strategyNames, child.Name) {
+			return fmt.Errorf("unknown composite child strategy %q", child.Name)
+		}
+		if child.Weight < 0 {
+			return fmt.Errorf("composite child weight must not be negative")
+		}
+		switch child.Role {
+		case "", "trigger":
+		case "filter":
+			filters++
+		default:
+			return fmt.Errorf("composite child role must be trigger or filter")
+		}
+	}
+
+	if composite.Rule == "trend_filter" && (filters == 0 || filters == len(composite.Strategies)) {
+		return fmt.Errorf("trend_filter rule requires at least one filter and one trigger strategy")
+	}
+
 	return nil
 }
+
+// Assets splits the traded symbol into its base and quote assets. A symbol
+// whose quote asset is not recognized is treated as quoted in
+// portfolio.DefaultCash.
+func (c *Config) Assets() (base, quote string) {
+	base, quote = market.SplitSymbol(c.Trading.Symbol)
+	if quote == "" {
+		quote = portfolio.DefaultCash
+	}
+	return base, quote
+}
+
+// Balances returns the starting balance of every asset: initial_balance in
+// the quote asset plus any initial_balances. Validate has checked that they
+// fit in a Decimal.
+func (c *Config) Balances() map[string]decimal.Decimal {
+	_, quote := c.Assets()
+	balances := map[string]decimal.Decimal{quote: decimal.MustFromFloat(c.Trading.InitialBalance)}
+	for asset, amount := range c.Trading.InitialBalances {
+		if asset != quote {
+			balances[asset] = decimal.MustFromFloat(amount)
+		}
+	}
+	return balances
+}
+
+// Reporting returns the currency portfolio values are reported in,
+// defaulting to the quote asset.
+func (c *Config) Reporting() string {
+	if c.Trading.ReportingCurrency != "" {
+		return c.Trading.ReportingCurrency
+	}
+	_, quote := c.Assets()
+	return quote
+}
+
+// CostMethod returns the configured lot accounting method, defaulting to
+// FIFO.
+func (c *Config) CostMethod() portfolio.CostMethod {
+	method, err := portfolio.ParseCostMethod(c.Trading.CostBasis)
+	if err != nil {
+		return portfolio.CostFIFO
+	}
+	return method
+}
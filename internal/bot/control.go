@@ -0,0 +1,299 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"time"
+
+	"trading-bot/internal/api"
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/journal"
+	"trading-bot/internal/portfolio"
+)
+
+const statusTransactions = 20
+
+// acquire waits for the bot to be between ticks and takes its turn, so an
+// API command sees and changes state no tick is using.
+func (bot *TradingBot) acquire(ctx context.Context) error {
+	select {
+	case <-bot.closed:
+		return api.ErrStopped
+	default:
+	}
+
+	select {
+	case bot.turn <- struct{}{}:
+		return nil
+	case <-bot.closed:
+		return api.ErrStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release publishes the status the turn left behind and hands the turn on.
+func (bot *TradingBot) release() {
+	bot.publishStatus()
+	<-bot.turn
+}
+
+// Status returns the status published when the last tick or command ended,
+// so it never waits for, or delays, a tick.
+func (bot *TradingBot) Status(ctx context.Context) (api.Status, error) {
+	select {
+	case <-bot.closed:
+		return api.Status{}, api.ErrStopped
+	default:
+	}
+
+	return *bot.status.Load(), nil
+}
+
+// publishStatus builds the status from state the caller's turn protects.
+func (bot *TradingBot) publishStatus() {
+	status := bot.buildStatus()
+	bot.status.Store(&status)
+}
+
+func (bot *TradingBot) buildStatus() api.Status {
+	snapshot := bot.portfolio.Snapshot(bot.lastPrices)
+	status := api.Status{
+		Symbol:            bot.config.Trading.Symbol,
+		Strategy:          bot.strategy.Name(),
+		DryRun:            bot.config.Bot.DryRun,
+		Paused:            bot.paused,
+		Halted:            bot.halted,
+		MaxRisk:           bot.config.Trading.MaxRisk,
+		CashAsset:         snapshot.Cash,
+		ReportingCurrency: snapshot.ReportingCurrency,
+		Balances:          snapshot.Balances,
+		Positions:         make([]api.Position, 0, len(snapshot.Positions)),
+		OpenOrders:        make([]api.Order, 0, len(bot.openOrders)),
+		Equity:            snapshot.Equity,
+		UnrealizedPnL:     snapshot.UnrealizedPnL(),
+	}
+	if len(snapshot.Unpriced()) == 0 {
+		status.Drawdown = bot.drawdown(snapshot.Equity)
+	}
+
+	if !bot.lastSignalAt.IsZero() {
+		status.Signal = &api.Signal{
+			Action:     string(bot.lastSignal.Action),
+			Confidence: bot.lastSignal.Confidence,
+			Reason:     bot.lastSignal.Reason,
+			Indicators: maps.Clone(bot.lastSignal.Indicators),
+			Time:       bot.lastSignalAt,
+		}
+	}
+
+	if ticks := bot.history.Ticks(); len(ticks) > 0 {
+		last := ticks[len(ticks)-1]
+		status.LastPrice, status.LastTick = last.Price, last.Timestamp
+	}
+
+	unrealized := bot.portfolio.GetUnrealizedPnL(bot.lastPrices)
+	for _, asset := range snapshot.Symbols() {
+		pos := snapshot.Positions[asset]
+		price := snapshot.Prices[asset]
+		status.Positions = append(status.Positions, api.Position{
+			Asset:         asset,
+			Quantity:      pos.Quantity,
+			Quote:         pos.Quote,
+			AverageCost:   pos.AveragePrice(),
+			Price:         price,
+			MarketValue:   pos.MarketValue(price),
+			UnrealizedPnL: unrealized[asset],
+		})
+	}
+
+	for _, order := range bot.openOrders {
+		status.OpenOrders = append(status.OpenOrders, apiOrder(order))
+	}
+
+	status.RealizedPnL = bot.portfolio.RealizedPnL()
+	status.RecentTransactions = apiTransactions(bot.portfolio.GetRecentTransactions(statusTransactions))
+
+	return status
+}
+
+func (bot *TradingBot) Pause(ctx context.Context) error {
+	if err := bot.acquire(ctx); err != nil {
+		return err
+	}
+	defer bot.release()
+
+	if !bot.paused {
+		bot.paused = true
+		bot.logger.Warn("Trading paused")
+	}
+	return nil
+}
+
+// Resume lifts a pause, and a reconciliation halt once the operator has
+// resolved the discrepancies.
+func (bot *TradingBot) Resume(ctx context.Context) error {
+	if err := bot.acquire(ctx); err != nil {
+		return err
+	}
+	defer bot.release()
+
+	if bot.paused || bot.halted {
+		bot.paused, bot.halted = false, false
+		bot.logger.Warn("Trading resumed")
+	}
+	return nil
+}
+
+// Flatten cancels the bot's open orders and sells its whole position in the
+// traded asset at market. It does not pause trading, so the strategy may
+// buy again on its next signal.
+func (bot *TradingBot) Flatten(ctx context.Context) ([]api.Transaction, error) {
+	if err := bot.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer bot.release()
+
+	// The request's context ends when the client disconnects; once started,
+	// the cancels and the sell must finish so they are booked.
+	work := context.WithoutCancel(ctx)
+
+	asset, _ := bot.config.Assets()
+	before := bot.portfolio.TransactionCount()
+
+	bot.cancelOpenOrders(work)
+	if len(bot.openOrders) > 0 {
+		return nil, fmt.Errorf("failed to cancel %d open orders", len(bot.openOrders))
+	}
+
+	quantity := bot.portfolio.GetPosition(asset)
+	if quantity.Sign() > 0 {
+		ticks := bot.history.Ticks()
+		if len(ticks) == 0 {
+			return nil, fmt.Errorf("no %s price received yet", bot.config.Trading.Symbol)
+		}
+		price := ticks[len(ticks)-1].Price
+
+		details := portfolio.TradeDetails{Strategy: "api", Reason: "flatten"}
+		if err := bot.execute(work, exchange.SideSell, quantity, exchange.TypeMarket, decimal.Zero, price, details); err != nil {
+			return nil, fmt.Errorf("failed to sell %s: %w", asset, err)
+		}
+	}
+
+	transactions := bot.portfolio.GetRecentTransactions(bot.portfolio.TransactionCount() - before)
+	bot.logger.Warn("Flattened position", "asset", asset, "quantity", quantity)
+	return apiTransactions(transactions), nil
+}
+
+// SetDryRun switches between simulated and live trading. Going live needs
+// a configuration that validates for live trading and a reachable exchange,
+// and replaces the simulated books with ones seeded from the account.
+func (bot *TradingBot) SetDryRun(ctx context.Context, dryRun bool) error {
+	if err := bot.acquire(ctx); err != nil {
+		return err
+	}
+	defer bot.release()
+
+	if bot.config.Bot.DryRun == dryRun {
+		return nil
+	}
+
+	if !dryRun {
+		live := *bot.config
+		live.Bot.DryRun = false
+		if err := live.Validate(); err != nil {
+			return fmt.Errorf("cannot trade live: %w", err)
+		}
+		if err := bot.exchange.TestConnection(ctx); err != nil {
+			return fmt.Errorf("cannot trade live: %w", err)
+		}
+		if err := bot.goLive(ctx); err != nil {
+			return fmt.Errorf("cannot trade live: %w", err)
+		}
+	}
+
+	bot.config.Bot.DryRun = dryRun
+	bot.logger.Warn("Switched trading mode", "dry_run", dryRun)
+	return nil
+}
+
+// goLive starts live books from the exchange account. Simulated orders are
+// closed and the simulated journal is archived, so neither its fills nor its
+// balances are mistaken for live ones after a restart.
+func (bot *TradingBot) goLive(ctx context.Context) error {
+	balances, err := bot.accountBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	bot.cancelOpenOrders(ctx)
+	if bot.journal != nil {
+		if err := bot.archiveJournal(); err != nil {
+			return err
+		}
+	}
+
+	bot.summarizedTrades = 0
+	bot.peakEquity = 0
+	return bot.startBooks(balances)
+}
+
+// archiveJournal moves the journal aside and starts an empty one in its
+// place. The old journal is restored if the new one cannot be opened.
+func (bot *TradingBot) archiveJournal() error {
+	file := bot.config.Bot.JournalFile
+	archive := fmt.Sprintf("%s.dry-run-%s", file, time.Now().Format("20060102-150405"))
+	if err := os.Rename(file, archive); err != nil {
+		return fmt.Errorf("error archiving journal: %w", err)
+	}
+
+	j, _, err := journal.Open(file)
+	if err != nil {
+		if err := os.Rename(archive, file); err != nil {
+			bot.logger.Error("Failed to restore journal", "archive", archive, "error", err)
+		}
+		return fmt.Errorf("failed to start journal: %w", err)
+	}
+	if err := bot.journal.Close(); err != nil {
+		bot.logger.Warn("Failed to close archived journal", "error", err)
+	}
+	bot.journal = j
+	bot.logger.Info("Archived dry-run journal", "archive", archive)
+	return nil
+}
+
+func apiOrder(order exchange.OrderResponse) api.Order {
+	return api.Order{
+		ID:          order.OrderID,
+		Symbol:      order.Symbol,
+		Side:        order.Side,
+		Type:        order.Type,
+		Status:      order.Status,
+		Quantity:    order.Quantity,
+		Price:       order.Price,
+		ExecutedQty: order.ExecutedQty,
+		CreatedAt:   time.UnixMilli(max(order.Time, order.TransactTime)),
+	}
+}
+
+func apiTransactions(transactions []portfolio.Transaction) []api.Transaction {
+	converted := make([]api.Transaction, len(transactions))
+	for i, t := range transactions {
+		converted[i] = api.Transaction{
+			Time:        t.Timestamp,
+			Type:        t.Type,
+			Symbol:      t.Symbol,
+			Quote:       t.Quote,
+			Amount:      t.Amount,
+			Price:       t.Price,
+			Total:       t.Total,
+			RealizedPnL: t.RealizedPnL,
+			Strategy:    t.Strategy,
+			Reason:      t.Reason,
+		}
+	}
+	return converted
+}
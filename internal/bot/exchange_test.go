@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/market"
+)
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+	os.Exit(m.Run())
+}
+
+// fakeExchange is an exchange.Exchange whose prices, account and order
+// reports the test sets.
+type fakeExchange struct {
+	mu      sync.Mutex
+	price   decimal.Decimal
+	account exchange.Account
+	// placed holds the responses PlaceOrder returns, in order.
+	placed []exchange.OrderResponse
+	// open is what GetOpenOrders returns; orders and cancels answer GetOrder
+	// and CancelOrder by order ID.
+	open    []exchange.OrderResponse
+	orders  map[int64]exchange.OrderResponse
+	cancels map[int64]exchange.OrderResponse
+}
+
+func newFakeExchange() *fakeExchange {
+	return &fakeExchange{
+		price:   decimal.MustParse("50000"),
+		orders:  make(map[int64]exchange.OrderResponse),
+		cancels: make(map[int64]exchange.OrderResponse),
+	}
+}
+
+func (f *fakeExchange) GetMarketData(ctx context.Context, symbol string) (*market.Data, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &market.Data{Symbol: symbol, Price: f.price}, nil
+}
+
+func (f *fakeExchange) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]market.Candle, error) {
+	return nil, nil
+}
+
+func (f *fakeExchange) PlaceOrder(ctx context.Context, symbol string, side exchange.Side, orderType exchange.OrderType, quantity, price decimal.Decimal) (*exchange.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.placed) == 0 {
+		return nil, fmt.Errorf("unexpected %s order", side)
+	}
+	order := f.placed[0]
+	f.placed = f.placed[1:]
+	return &order, nil
+}
+
+func (f *fakeExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) (*exchange.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.cancels[orderID]
+	if !ok {
+		return nil, fmt.Errorf("unknown order %d", orderID)
+	}
+	return &order, nil
+}
+
+func (f *fakeExchange) GetOrder(ctx context.Context, symbol string, orderID int64) (*exchange.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("unknown order %d", orderID)
+	}
+	return &order, nil
+}
+
+func (f *fakeExchange) GetOpenOrders(ctx context.Context, symbol string) ([]exchange.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]exchange.OrderResponse(nil), f.open...), nil
+}
+
+func (f *fakeExchange) GetAccount(ctx context.Context) (*exchange.Account, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	account := f.account
+	account.Balances = append([]exchange.Balance(nil), f.account.Balances...)
+	return &account, nil
+}
+
+func (f *fakeExchange) TestConnection(ctx context.Context) error {
+	return nil
+}
+
+// testConfig returns the default configuration with its files in a
+// temporary directory, warm-up off and a tick interval long enough that
+// tests drive the bot themselves.
+func testConfig(t *testing.T) *Config {
+	t.Helper()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.json")
+	if err := CreateDefaultConfig(filename); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.Binance.APIKey, config.Binance.SecretKey = "key", "secret"
+	config.Bot.IntervalSeconds = 3600
+	config.Bot.JournalFile = filepath.Join(dir, "journal.log")
+	config.Bot.StateFile = ""
+	config.Bot.Equity.File = ""
+	config.Bot.WarmUp.Enabled = false
+	return config
+}
+
+// newTestBot builds a bot for config trading on fake.
+func newTestBot(t *testing.T, config *Config, fake *fakeExchange) *TradingBot {
+	t.Helper()
+	bot, err := NewTradingBot(config)
+	if err != nil {
+		t.Fatalf("NewTradingBot failed: %v", err)
+	}
+	bot.exchange = fake
+	bot.out = io.Discard
+	t.Cleanup(func() {
+		if bot.journal != nil {
+			bot.journal.Close()
+		}
+	})
+	return bot
+}
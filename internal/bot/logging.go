@@ -0,0 +1,39 @@
+package bot
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// NewLogger builds the logger bot.log_level and bot.log_format configure,
+// writing to w. Components derive their own loggers from it with a
+// "component" field.
+func NewLogger(config *Config, w io.Writer) (*slog.Logger, error) {
+	level, err := parseLogLevel(config.Bot.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if config.Bot.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(w, options)), nil
+	}
+	return slog.New(slog.NewTextHandler(w, options)), nil
+}
+
+// parseLogLevel accepts debug, info, warn or error; empty means info.
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("log level must be debug, info, warn or error")
+}
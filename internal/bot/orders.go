@@ -0,0 +1,265 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/events"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/portfolio"
+	"trading-bot/internal/strategy"
+)
+
+// Orders are booked as they execute, not when they are placed. openOrders
+// holds each resting order as far as it has been booked: its ExecutedQty and
+// CumulativeQuoteQty are what the portfolio already reflects, and each
+// refresh books whatever the exchange reports beyond them.
+
+// execute orders quantity of the traded asset and books whatever of it
+// executes right away, at the price the exchange reports. Dry runs fill
+// market orders at marketPrice and rest limit orders until a tick crosses
+// them. Failures are logged and published here.
+func (bot *TradingBot) execute(ctx context.Context, side exchange.Side, quantity decimal.Decimal, orderType exchange.OrderType, limitPrice, marketPrice decimal.Decimal, details portfolio.TradeDetails) error {
+	if bot.config.Bot.DryRun {
+		return bot.simulate(side, quantity, orderType, limitPrice, marketPrice, details)
+	}
+
+	order, err := bot.exchange.PlaceOrder(ctx, bot.config.Trading.Symbol, side, orderType, quantity, limitPrice)
+	if err != nil {
+		bot.logger.Error("Failed to place order", "side", side, "quantity", quantity, "error", err)
+		bot.publishRejected(side, quantity, limitPrice, err)
+		return err
+	}
+	bot.bus.Publish(events.OrderSubmitted{Order: *order})
+
+	placed := *order
+	placed.ExecutedQty, placed.CumulativeQuoteQty = decimal.Zero, decimal.Zero
+	if order.Open() {
+		bot.orderDetails[order.OrderID] = details
+		bot.openOrders = append(bot.openOrders, *order)
+	}
+	return bot.settle(placed, *order, details)
+}
+
+// simulate fills a dry-run order. Market orders and limit orders the price
+// has already reached fill in full; other limit orders rest until
+// fillSimulated sees a tick cross them.
+func (bot *TradingBot) simulate(side exchange.Side, quantity decimal.Decimal, orderType exchange.OrderType, limitPrice, marketPrice decimal.Decimal, details portfolio.TradeDetails) error {
+	if orderType == exchange.TypeMarket {
+		return bot.book(string(side), quantity, marketPrice, details)
+	}
+
+	order := exchange.OrderResponse{
+		Symbol:   bot.config.Trading.Symbol,
+		OrderID:  bot.simulatedOrderID(),
+		Status:   "NEW",
+		Type:     string(orderType),
+		Side:     string(side),
+		Quantity: quantity,
+		Price:    limitPrice,
+		Time:     time.Now().UnixMilli(),
+	}
+	if crosses(order, marketPrice) {
+		return bot.book(order.Side, quantity, limitPrice, details)
+	}
+
+	bot.journalOrder(order)
+	bot.orderDetails[order.OrderID] = details
+	bot.openOrders = append(bot.openOrders, order)
+	bot.logger.Info("Simulated limit order resting", "order_id", order.OrderID, "side", side, "quantity", quantity, "price", limitPrice)
+	return nil
+}
+
+// simulatedOrderID numbers dry-run orders by creation time, so they stay
+// unique across restarts without being journaled when they fill at once.
+func (bot *TradingBot) simulatedOrderID() int64 {
+	bot.lastSimulatedID = max(time.Now().UnixMilli(), bot.lastSimulatedID+1)
+	return bot.lastSimulatedID
+}
+
+// crosses reports whether a limit order is marketable at price.
+func crosses(order exchange.OrderResponse, price decimal.Decimal) bool {
+	if order.Side == string(exchange.SideBuy) {
+		return price.Cmp(order.Price) <= 0
+	}
+	return price.Cmp(order.Price) >= 0
+}
+
+// refreshOpenOrders books what the tracked orders executed since the last
+// refresh. It only queries the exchange while the bot believes it has resting
+// orders, so market-order-only strategies cost no extra requests. Orders the
+// bot did not place are left for reconciliation to report.
+func (bot *TradingBot) refreshOpenOrders(ctx context.Context) {
+	if len(bot.openOrders) == 0 {
+		return
+	}
+	if bot.config.Bot.DryRun {
+		bot.fillSimulated()
+		return
+	}
+
+	orders, err := bot.exchange.GetOpenOrders(ctx, bot.config.Trading.Symbol)
+	if err != nil {
+		bot.logger.Warn("Failed to refresh open orders", "error", err)
+		return
+	}
+	latest := make(map[int64]exchange.OrderResponse, len(orders))
+	for _, order := range orders {
+		latest[order.OrderID] = order
+	}
+
+	open := make([]exchange.OrderResponse, 0, len(bot.openOrders))
+	for _, tracked := range bot.openOrders {
+		current, ok := latest[tracked.OrderID]
+		if !ok {
+			// The order left the book: it filled, was cancelled or expired.
+			report, err := bot.exchange.GetOrder(ctx, tracked.Symbol, tracked.OrderID)
+			if err != nil {
+				bot.logger.Warn("Failed to fetch order", "order_id", tracked.OrderID, "error", err)
+				open = append(open, tracked)
+				continue
+			}
+			current = *report
+		}
+
+		bot.update(tracked, current)
+		if current.Open() {
+			open = append(open, current)
+		}
+	}
+	bot.openOrders = open
+}
+
+// fillSimulated fills resting dry-run orders in full at their limit price
+// once the last tick crosses it.
+func (bot *TradingBot) fillSimulated() {
+	ticks := bot.history.Ticks()
+	if len(ticks) == 0 {
+		return
+	}
+	price := ticks[len(ticks)-1].Price
+
+	open := make([]exchange.OrderResponse, 0, len(bot.openOrders))
+	for _, order := range bot.openOrders {
+		if !crosses(order, price) {
+			open = append(open, order)
+			continue
+		}
+
+		filled := order
+		filled.Status = "FILLED"
+		filled.ExecutedQty = order.Quantity
+		// A cost too large for a Decimal fails to book below, with an error.
+		filled.CumulativeQuoteQty, _ = order.Quantity.Mul(order.Price)
+		bot.book(order.Side, order.Quantity, order.Price, bot.detailsFor(order))
+		bot.journalOrder(filled)
+		delete(bot.orderDetails, order.OrderID)
+	}
+	bot.openOrders = open
+}
+
+// update books an order's progress between two reports of it and journals
+// the newer one. Reports that show no change are ignored.
+func (bot *TradingBot) update(previous, current exchange.OrderResponse) {
+	if current.Status == previous.Status && current.ExecutedQty.Cmp(previous.ExecutedQty) == 0 {
+		return
+	}
+	bot.settle(previous, current, bot.detailsFor(current))
+	bot.journalOrder(current)
+	if !current.Open() {
+		delete(bot.orderDetails, current.OrderID)
+		bot.logger.Info("Order closed", "order_id", current.OrderID, "status", current.Status, "executed", current.ExecutedQty, "quantity", current.Quantity)
+	}
+}
+
+// settle books the quantity an order executed between two reports of it, at
+// the average price of that execution.
+func (bot *TradingBot) settle(previous, current exchange.OrderResponse, details portfolio.TradeDetails) error {
+	quantity, price, err := execution(previous, current)
+	if err != nil {
+		bot.logger.Error("Failed to book fill", "order_id", current.OrderID, "error", err)
+		bot.publishError("portfolio", err)
+		return err
+	}
+	if quantity.Sign() <= 0 {
+		return nil
+	}
+	return bot.book(current.Side, quantity, price, details)
+}
+
+// execution returns the quantity an order executed between two reports of it
+// and the average price it executed at. The quantity is zero when nothing
+// executed.
+func execution(previous, current exchange.OrderResponse) (quantity, price decimal.Decimal, err error) {
+	quantity, err = current.ExecutedQty.Sub(previous.ExecutedQty)
+	if err != nil || quantity.Sign() <= 0 {
+		return decimal.Zero, decimal.Zero, err
+	}
+
+	price = current.Price
+	quote, err := current.CumulativeQuoteQty.Sub(previous.CumulativeQuoteQty)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	if quote.Sign() > 0 {
+		if price, err = quote.Div(quantity); err != nil {
+			return decimal.Zero, decimal.Zero, err
+		}
+	}
+	if price.Sign() <= 0 {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("order %d executed %s without a fill price", current.OrderID, quantity)
+	}
+	return quantity, price, nil
+}
+
+// book adds an executed buy or sell of the traded asset to the portfolio,
+// journals it and publishes the fill.
+func (bot *TradingBot) book(side string, quantity, price decimal.Decimal, details portfolio.TradeDetails) error {
+	asset, quote := bot.config.Assets()
+	book := bot.portfolio.BuyPair
+	if side == string(exchange.SideSell) {
+		book = bot.portfolio.SellPair
+	}
+
+	if err := book(asset, quote, quantity, price, details); err != nil {
+		bot.logger.Error("Failed to update portfolio", "error", err)
+		bot.publishError("portfolio", err)
+		return err
+	}
+	t := bot.portfolio.GetRecentTransactions(1)[0]
+	bot.journalTransaction(t)
+	bot.bus.Publish(events.OrderFilled{Transaction: t, DryRun: bot.config.Bot.DryRun})
+	return nil
+}
+
+// detailsFor returns the signal details an order was placed with. Orders
+// restored from the journal or adopted from the exchange have none.
+func (bot *TradingBot) detailsFor(order exchange.OrderResponse) portfolio.TradeDetails {
+	if details, ok := bot.orderDetails[order.OrderID]; ok {
+		return details
+	}
+	return portfolio.TradeDetails{Reason: fmt.Sprintf("fill of order %d", order.OrderID)}
+}
+
+// rejectSignal reports a signal whose amount or price cannot be turned into
+// an order.
+func (bot *TradingBot) rejectSignal(signal strategy.Signal, err error) {
+	side := exchange.SideBuy
+	if signal.Action == strategy.ActionSell {
+		side = exchange.SideSell
+	}
+	bot.logger.Warn("Rejected signal", "action", signal.Action, "amount", signal.Amount, "limit_price", signal.LimitPrice, "error", err)
+	bot.publishRejected(side, decimal.Zero, decimal.Zero, err)
+}
+
+func (bot *TradingBot) publishRejected(side exchange.Side, quantity, price decimal.Decimal, err error) {
+	bot.bus.Publish(events.OrderRejected{
+		Symbol:   bot.config.Trading.Symbol,
+		Side:     side,
+		Quantity: quantity,
+		Price:    price,
+		Reason:   err.Error(),
+	})
+}
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/journal"
+	"trading-bot/internal/portfolio"
+)
+
+// report is the exchange's view of a 0.02 BTC limit buy at 50000 with
+// executed BTC filled for quote USDT.
+func report(status, executed, quote string) exchange.OrderResponse {
+	return exchange.OrderResponse{
+		Symbol:             "BTCUSDT",
+		OrderID:            1,
+		Status:             status,
+		Type:               string(exchange.TypeLimit),
+		Side:               string(exchange.SideBuy),
+		Quantity:           decimal.MustParse("0.02"),
+		Price:              decimal.MustParse("50000"),
+		ExecutedQty:        decimal.MustParse(executed),
+		CumulativeQuoteQty: decimal.MustParse(quote),
+	}
+}
+
+// orderStep is what the exchange reports on one refresh, or on a cancel.
+type orderStep struct {
+	report exchange.OrderResponse
+	cancel bool
+}
+
+func TestOrderExecutionBooksEachFillOnce(t *testing.T) {
+	tests := []struct {
+		name     string
+		placed   exchange.OrderResponse
+		steps    []orderStep
+		position string
+		cash     string
+		fills    int
+	}{
+		{
+			name:   "partial then full fill",
+			placed: report("NEW", "0", "0"),
+			steps: []orderStep{
+				{report: report("PARTIALLY_FILLED", "0.01", "500")},
+				{report: report("FILLED", "0.02", "1000")},
+			},
+			position: "0.02", cash: "9000", fills: 2,
+		},
+		{
+			name:   "partial fill on placement",
+			placed: report("PARTIALLY_FILLED", "0.005", "250"),
+			steps: []orderStep{
+				{report: report("FILLED", "0.02", "999")},
+			},
+			position: "0.02", cash: "9001", fills: 2,
+		},
+		{
+			name:   "cancel after a partial fill",
+			placed: report("NEW", "0", "0"),
+			steps: []orderStep{
+				{report: report("PARTIALLY_FILLED", "0.01", "500")},
+				{report: report("CANCELED", "0.01", "500"), cancel: true},
+			},
+			position: "0.01", cash: "9500", fills: 1,
+		},
+		{
+			name:   "cancel reporting a last fill",
+			placed: report("NEW", "0", "0"),
+			steps: []orderStep{
+				{report: report("PARTIALLY_FILLED", "0.01", "500")},
+				{report: report("CANCELED", "0.015", "750"), cancel: true},
+			},
+			position: "0.015", cash: "9250", fills: 2,
+		},
+		{
+			name:   "repeated execution reports",
+			placed: report("NEW", "0", "0"),
+			steps: []orderStep{
+				{report: report("PARTIALLY_FILLED", "0.01", "500")},
+				{report: report("PARTIALLY_FILLED", "0.01", "500")},
+				{report: report("FILLED", "0.02", "1000")},
+				{report: report("FILLED", "0.02", "1000")},
+			},
+			position: "0.02", cash: "9000", fills: 2,
+		},
+		{
+			name:     "filled on placement",
+			placed:   report("FILLED", "0.02", "1000"),
+			position: "0.02", cash: "9000", fills: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig(t)
+			config.Bot.DryRun = false
+			fake := newFakeExchange()
+			fake.placed = []exchange.OrderResponse{tt.placed}
+			bot := newTestBot(t, config, fake)
+			ctx := context.Background()
+
+			details := portfolio.TradeDetails{Strategy: "test"}
+			if err := bot.execute(ctx, exchange.SideBuy, tt.placed.Quantity, exchange.TypeLimit, tt.placed.Price, tt.placed.Price, details); err != nil {
+				t.Fatalf("execute failed: %v", err)
+			}
+
+			for _, step := range tt.steps {
+				fake.open = nil
+				if step.cancel {
+					fake.cancels[step.report.OrderID] = step.report
+					bot.cancelOpenOrders(ctx)
+					continue
+				}
+				if step.report.Open() {
+					fake.open = []exchange.OrderResponse{step.report}
+				} else {
+					fake.orders[step.report.OrderID] = step.report
+				}
+				bot.refreshOpenOrders(ctx)
+			}
+
+			if got := bot.portfolio.GetPosition("BTC").String(); got != tt.position {
+				t.Errorf("position = %s, want %s", got, tt.position)
+			}
+			if got := bot.portfolio.GetBalance().String(); got != tt.cash {
+				t.Errorf("cash = %s, want %s", got, tt.cash)
+			}
+			if got := len(bot.portfolio.GetHistory()); got != tt.fills {
+				t.Errorf("%d transactions booked, want %d", got, tt.fills)
+			}
+			if len(bot.openOrders) != 0 {
+				t.Errorf("orders still tracked: %+v", bot.openOrders)
+			}
+
+			_, records, err := journal.Open(config.Bot.JournalFile)
+			if err != nil {
+				t.Fatalf("reopening the journal failed: %v", err)
+			}
+			journaled := 0
+			for _, record := range records {
+				if record.Kind == journal.KindTransaction {
+					journaled++
+				}
+			}
+			if journaled != tt.fills {
+				t.Errorf("%d transactions journaled, want %d", journaled, tt.fills)
+			}
+		})
+	}
+}
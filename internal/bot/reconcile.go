@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/events"
+	"trading-bot/internal/portfolio"
+	"trading-bot/internal/reconcile"
+)
+
+// seedFromAccount starts the books from the exchange account's balances of
+// the configured assets, so a new live journal matches the account instead
+// of reporting trading.initial_balance as a permanent discrepancy.
+func (bot *TradingBot) seedFromAccount(ctx context.Context) error {
+	balances, err := bot.accountBalances(ctx)
+	if err != nil {
+		return err
+	}
+	return bot.startBooks(balances)
+}
+
+// accountBalances returns the exchange account's total of the traded asset
+// and every asset the configuration gives a starting balance.
+func (bot *TradingBot) accountBalances(ctx context.Context) (map[string]decimal.Decimal, error) {
+	account, err := bot.exchange.GetAccount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching account: %w", err)
+	}
+
+	base, _ := bot.config.Assets()
+	assets := []string{base}
+	for asset := range bot.config.Balances() {
+		assets = append(assets, asset)
+	}
+	balances := make(map[string]decimal.Decimal, len(assets))
+	for _, asset := range assets {
+		if balances[asset], err = account.Total(asset); err != nil {
+			return nil, fmt.Errorf("error reading %s balance: %w", asset, err)
+		}
+	}
+	return balances, nil
+}
+
+// startBooks replaces the books with new ones holding balances and writes
+// them to the journal as its genesis.
+func (bot *TradingBot) startBooks(balances map[string]decimal.Decimal) error {
+	bot.portfolio = newPortfolio(bot.config, balances)
+	if bot.journal != nil {
+		if err := bot.journal.AppendGenesis(balances, bot.portfolio.CashAsset()); err != nil {
+			return fmt.Errorf("failed to start journal: %w", err)
+		}
+	}
+	bot.seedBooks = false
+
+	base, _ := bot.config.Assets()
+	bot.logger.Info("Seeded books from exchange account", "cash", bot.portfolio.GetBalance(), "cash_asset", bot.portfolio.CashAsset(), "position", balances[base], "asset", base)
+	return nil
+}
+
+// reconcileIfDue compares the local books with the exchange account once per
+// configured interval. Dry runs have no account to compare with.
+func (bot *TradingBot) reconcileIfDue(ctx context.Context) {
+	settings := bot.config.Bot.Reconcile
+	if bot.config.Bot.DryRun || !settings.Enabled {
+		return
+	}
+	if time.Since(bot.lastReconcile) < time.Duration(settings.IntervalSeconds)*time.Second {
+		return
+	}
+	bot.lastReconcile = time.Now()
+
+	if err := bot.reconcile(ctx); err != nil {
+		bot.logger.Error("Reconciliation failed", "error", err)
+		bot.publishError("reconcile", err)
+	}
+}
+
+func (bot *TradingBot) reconcile(ctx context.Context) error {
+	symbol := bot.config.Trading.Symbol
+	base, quote := bot.config.Assets()
+
+	// Book what tracked orders executed first, so fills are not reported as
+	// discrepancies.
+	bot.refreshOpenOrders(ctx)
+	account, err := bot.exchange.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching account: %w", err)
+	}
+	orders, err := bot.exchange.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("error fetching open orders: %w", err)
+	}
+
+	discrepancies, err := reconcile.Compare(reconcile.Local{
+		BaseAsset:  base,
+		QuoteAsset: quote,
+		Cash:       bot.portfolio.GetPosition(quote),
+		Position:   bot.portfolio.GetPosition(base),
+		OpenOrders: bot.openOrders,
+	}, account, orders, bot.config.Bot.Reconcile.Tolerance)
+	if err != nil {
+		return fmt.Errorf("error comparing balances: %w", err)
+	}
+
+	if len(discrepancies) == 0 {
+		return nil
+	}
+
+	policy, _ := reconcile.ParsePolicy(bot.config.Bot.Reconcile.Policy)
+	for _, d := range discrepancies {
+		bot.logger.Warn("Reconciliation discrepancy", "policy", policy, "kind", d.Kind, "detail", d.String())
+	}
+
+	switch policy {
+	case reconcile.PolicyAdopt:
+		bot.adopt(discrepancies, base)
+		bot.openOrders = orders
+		for _, order := range orders {
+			bot.journalOrder(order)
+		}
+	case reconcile.PolicyHalt:
+		if !bot.halted {
+			bot.halted = true
+			bot.logger.Error("Trading halted after reconciliation discrepancies; restart once resolved", "discrepancies", len(discrepancies))
+			bot.bus.Publish(events.RiskLimitHit{
+				Limit:  "halted",
+				Detail: fmt.Sprintf("%d reconciliation discrepancies", len(discrepancies)),
+			})
+		}
+	}
+	return nil
+}
+
+// adopt moves the local balance and position by the difference the exchange
+// reports. New quantity is valued at the latest price.
+func (bot *TradingBot) adopt(discrepancies []reconcile.Discrepancy, base string) {
+	price := decimal.Zero
+	if ticks := bot.history.Ticks(); len(ticks) > 0 {
+		price = ticks[len(ticks)-1].Price
+	}
+
+	for _, d := range discrepancies {
+		var t portfolio.Transaction
+		var err error
+		switch d.Kind {
+		case reconcile.KindBalance:
+			t, err = bot.portfolio.AdjustBalanceBy(d.Difference(), "adopted exchange balance")
+		case reconcile.KindPosition:
+			t, err = bot.portfolio.AdjustPositionBy(base, d.Difference(), price, "adopted exchange position")
+		default:
+			continue
+		}
+		if err != nil {
+			bot.logger.Error("Failed to adopt exchange balance", "asset", d.Asset, "error", err)
+			bot.publishError("reconcile", err)
+			continue
+		}
+		bot.journalTransaction(t)
+	}
+}
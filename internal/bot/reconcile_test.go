@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/events"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/journal"
+)
+
+func TestLiveStartSeedsBooksFromAccount(t *testing.T) {
+	config := testConfig(t)
+	config.Bot.DryRun = false
+	config.Bot.Reconcile.Policy = "halt"
+
+	fake := newFakeExchange()
+	fake.account.Balances = []exchange.Balance{
+		{Asset: "USDT", Free: decimal.MustParse("2400"), Locked: decimal.MustParse("100")},
+		{Asset: "BTC", Free: decimal.MustParse("0.1")},
+		{Asset: "ETH", Free: decimal.MustParse("3")},
+	}
+	bot := newTestBot(t, config, fake)
+	started := bot.Events().Subscribe(1, events.KindStarted)
+
+	done := make(chan error, 1)
+	go func() { done <- bot.Start(context.Background()) }()
+	select {
+	case <-started.C():
+	case err := <-done:
+		t.Fatalf("Start returned early: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("bot did not start")
+	}
+	bot.Stop()
+	if err := <-done; err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if bot.halted {
+		t.Error("the first reconciliation found discrepancies with the seeded books")
+	}
+	if got := bot.portfolio.GetBalance().String(); got != "2500" {
+		t.Errorf("cash = %s, want 2500", got)
+	}
+
+	_, records, err := journal.Open(config.Bot.JournalFile)
+	if err != nil {
+		t.Fatalf("reopening the journal failed: %v", err)
+	}
+	if len(records) == 0 || records[0].Kind != journal.KindGenesis {
+		t.Fatalf("journal does not start with a genesis record: %+v", records)
+	}
+	genesis := records[0].Balances
+	if len(genesis) != 2 || genesis["USDT"].String() != "2500" || genesis["BTC"].String() != "0.1" {
+		t.Errorf("genesis balances = %v, want 2500 USDT and 0.1 BTC", genesis)
+	}
+}
+
+func TestDryRunStartUsesInitialBalance(t *testing.T) {
+	config := testConfig(t)
+	bot := newTestBot(t, config, newFakeExchange())
+
+	if bot.seedBooks {
+		t.Error("dry-run books are waiting to be seeded from an account")
+	}
+	_, records, err := journal.Open(config.Bot.JournalFile)
+	if err != nil {
+		t.Fatalf("reopening the journal failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Balances["USDT"].String() != "10000" {
+		t.Errorf("genesis = %+v, want the 10000 USDT initial balance", records)
+	}
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"trading-bot/internal/events"
+	"trading-bot/internal/exchange"
+)
+
+const defaultShutdownTimeout = 10 * time.Second
+
+// shutdown waits for the tick or API command in flight, cancelling a tick's
+// requests if it is still running when the shutdown timeout expires, then
+// optionally cancels open orders and flushes state. Cancelling orders and
+// stopping the servers share the same deadline. A tick that outlives a
+// second timeout after cancellation is abandoned.
+func (bot *TradingBot) shutdown(cancelWork context.CancelFunc) error {
+	bot.logger.Info("Shutting down trading bot")
+
+	timeout := time.Duration(bot.config.Bot.Shutdown.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	held := true
+	select {
+	case bot.turn <- struct{}{}:
+	default:
+		bot.logger.Info("Waiting for the current tick to finish")
+		select {
+		case bot.turn <- struct{}{}:
+		case <-ctx.Done():
+			bot.logger.Warn("Tick still running, cancelling it", "timeout", timeout)
+			cancelWork()
+			select {
+			case bot.turn <- struct{}{}:
+			case <-time.After(timeout):
+				held = false
+				bot.logger.Error("Tick ignored cancellation, stopping without it", "timeout", timeout)
+			}
+		}
+	}
+	close(bot.closed)
+
+	// The books and strategy belong to the tick until it returns, so they
+	// are left alone if it never did.
+	if held {
+		if bot.config.Bot.Shutdown.CancelOpenOrders {
+			bot.cancelOpenOrders(ctx)
+		}
+		bot.saveStrategyState()
+	}
+	if bot.journal != nil {
+		if err := bot.journal.Close(); err != nil {
+			bot.logger.Error("Failed to close journal", "error", err)
+		}
+	}
+
+	if bot.apiServer != nil {
+		if err := bot.apiServer.Shutdown(ctx); err != nil {
+			bot.logger.Error("Failed to stop API server", "error", err)
+		}
+	}
+	if bot.telemetry != nil {
+		if err := bot.telemetry.close(ctx); err != nil {
+			bot.logger.Error("Failed to stop metrics server", "error", err)
+		}
+	}
+
+	bot.logger.Info("Trading bot stopped")
+	bot.bus.Publish(events.Stopped{Symbol: bot.config.Trading.Symbol})
+	bot.bus.Close()
+	return nil
+}
+
+// cancelOpenOrders cancels every order the bot tracks, booking whatever
+// executed since the last refresh. Simulated orders are simply closed.
+func (bot *TradingBot) cancelOpenOrders(ctx context.Context) {
+	remaining := make([]exchange.OrderResponse, 0)
+	for _, order := range bot.openOrders {
+		if bot.config.Bot.DryRun {
+			cancelled := order
+			cancelled.Status = "CANCELED"
+			bot.update(order, cancelled)
+			continue
+		}
+
+		cancelled, err := bot.exchange.CancelOrder(ctx, order.Symbol, order.OrderID)
+		if err != nil {
+			bot.logger.Error("Failed to cancel order", "order_id", order.OrderID, "error", err)
+			bot.publishError("exchange", err)
+			remaining = append(remaining, order)
+			continue
+		}
+		bot.update(order, *cancelled)
+	}
+	bot.openOrders = remaining
+}
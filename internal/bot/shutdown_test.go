@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShutdownAbandonsStuckTick(t *testing.T) {
+	config := testConfig(t)
+	config.Bot.Shutdown.TimeoutSeconds = 1
+	bot := newTestBot(t, config, newFakeExchange())
+
+	// A tick that holds the turn and ignores cancellation.
+	bot.turn <- struct{}{}
+	cancelled := false
+	done := make(chan error, 1)
+	go func() { done <- bot.shutdown(func() { cancelled = true }) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("shutdown failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown is still waiting for the tick")
+	}
+	if !cancelled {
+		t.Error("the tick's work was not cancelled")
+	}
+	select {
+	case <-bot.closed:
+	default:
+		t.Error("the bot was not marked closed")
+	}
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"trading-bot/internal/market"
+	"trading-bot/internal/strategy"
+)
+
+// strategyNames lists the names trading.strategy and composite children
+// accept.
+var strategyNames = []string{"moving_average", "rsi", "macd", "bollinger", "multi_timeframe", "script", "composite"}
+
+func NewStrategy(name string, config *Config) (strategy.Strategy, error) {
+	switch name {
+	case "moving_average":
+		ma := config.Trading.MovingAverage
+		return strategy.NewMovingAverageStrategyWithConfig(strategy.MovingAverageConfig{
+			ShortPeriod:      ma.ShortPeriod,
+			LongPeriod:       ma.LongPeriod,
+			Type:             strategy.MAType(ma.Type),
+			ConfirmationBars: ma.ConfirmationBars,
+			PositionAware:    ma.PositionAware,
+		}), nil
+	case "rsi":
+		return strategy.NewRSIStrategy(14), nil
+	case "macd":
+		macd := config.Trading.MACD
+		return strategy.NewMACDStrategy(strategy.MACDConfig{
+			FastPeriod:         macd.FastPeriod,
+			SlowPeriod:         macd.SlowPeriod,
+			SignalPeriod:       macd.SignalPeriod,
+			HistogramFilter:    macd.HistogramFilter,
+			DivergenceFilter:   macd.DivergenceFilter,
+			DivergenceLookback: macd.DivergenceLookback,
+		}), nil
+	case "bollinger":
+		bb := config.Trading.Bollinger
+		var interval time.Duration
+		if bb.Interval != "" {
+			var err error
+			if interval, err = market.ParseInterval(bb.Interval); err != nil {
+				return nil, fmt.Errorf("invalid bollinger interval: %w", err)
+			}
+		}
+		return strategy.NewBollingerStrategy(strategy.BollingerConfig{
+			Period:           bb.Period,
+			StdDev:           bb.StdDev,
+			Mode:             strategy.BollingerMode(bb.Mode),
+			Interval:         interval,
+			VolumePeriod:     bb.VolumePeriod,
+			VolumeMultiplier: bb.VolumeMultiplier,
+		}), nil
+	case "multi_timeframe":
+		mtf := config.Trading.MultiTimeframe
+		return strategy.NewMultiTimeframeStrategy(strategy.MultiTimeframeConfig{
+			EntryInterval: mtf.EntryInterval,
+			TrendInterval: mtf.TrendInterval,
+			FastPeriod:    mtf.FastPeriod,
+			SlowPeriod:    mtf.SlowPeriod,
+			TrendPeriod:   mtf.TrendPeriod,
+		})
+	case "script":
+		sc := config.Trading.Script
+		var interval time.Duration
+		if sc.Interval != "" {
+			var err error
+			if interval, err = market.ParseInterval(sc.Interval); err != nil {
+				return nil, fmt.Errorf("invalid script interval: %w", err)
+			}
+		}
+		return strategy.NewScriptedStrategy(strategy.ScriptConfig{
+			Entry:    sc.Entry,
+			Exit:     sc.Exit,
+			Interval: interval,
+		})
+	case "composite":
+		return newCompositeStrategy(config)
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", name)
+	}
+}
+
+func newCompositeStrategy(config *Config) (strategy.Strategy, error) {
+	composite := config.Trading.Composite
+	if len(composite.Strategies) == 0 {
+		return nil, fmt.Errorf("composite strategy has no child strategies")
+	}
+
+	children := make([]strategy.CompositeChild, 0, len(composite.Strategies))
+	for _, child := range composite.Strategies {
+		if child.Name == "composite" {
+			return nil, fmt.Errorf("composite strategy cannot contain another composite strategy")
+		}
+
+		strat, err := NewStrategy(child.Name, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s child strategy: %w", child.Name, err)
+		}
+
+		children = append(children, strategy.CompositeChild{
+			Strategy: strat,
+			Weight:   child.Weight,
+			Role:     strategy.ChildRole(child.Role),
+		})
+	}
+
+	return strategy.NewCompositeStrategy(strategy.CombineRule(composite.Rule), composite.Threshold, children), nil
+}
@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTradingBotRejectsVolumeBreakout(t *testing.T) {
+	config := testConfig(t)
+	config.Trading.Strategy = "bollinger"
+	config.Trading.Bollinger.Mode = "breakout"
+
+	if _, err := NewTradingBot(config); err == nil || !strings.Contains(err.Error(), "volume") {
+		t.Fatalf("NewTradingBot error = %v, want a volume error", err)
+	}
+
+	config.Trading.Bollinger.VolumeMultiplier = 0
+	newTestBot(t, config, newFakeExchange())
+}
+
+func TestUnknownStrategyNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		children []CompositeChildConfig
+	}{
+		{name: "top level", strategy: "moving_averge"},
+		{name: "composite child", strategy: "composite", children: []CompositeChildConfig{
+			{Name: "moving_average", Role: "filter"},
+			{Name: "rsi14", Role: "trigger"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig(t)
+			config.Trading.Strategy = tt.strategy
+			config.Trading.Composite.Strategies = tt.children
+
+			if err := config.Validate(); err == nil {
+				t.Error("Validate accepted an unknown strategy name")
+			}
+			if _, err := NewStrategy(tt.strategy, config); err == nil {
+				t.Error("NewStrategy built a strategy for an unknown name")
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"trading-bot/internal/events"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/telemetry"
+)
+
+// botTelemetry holds the series the bot exports for Prometheus. Counters
+// follow the event bus and the exchange client's request hook; gauges are
+// set from the portfolio once per tick.
+type botTelemetry struct {
+	registry *telemetry.Registry
+	server   *http.Server
+
+	ticks      *telemetry.Counter
+	signals    *telemetry.Counter
+	orders     *telemetry.Counter
+	riskLimits *telemetry.Counter
+	errors     *telemetry.Counter
+
+	apiRequests *telemetry.Counter
+	apiErrors   *telemetry.Counter
+	apiLatency  *telemetry.Histogram
+	apiWeight   *telemetry.Gauge
+
+	equity    *telemetry.Gauge
+	cash      *telemetry.Gauge
+	positions *telemetry.Gauge
+	drawdown  *telemetry.Gauge
+}
+
+func newBotTelemetry() *botTelemetry {
+	r := telemetry.NewRegistry()
+	return &botTelemetry{
+		registry: r,
+
+		ticks:      r.Counter("trading_bot_ticks_total", "Market data ticks processed."),
+		signals:    r.Counter("trading_bot_signals_total", "Buy and sell signals generated, by action.", "action"),
+		orders:     r.Counter("trading_bot_orders_total", "Orders by outcome: submitted, filled or rejected.", "status"),
+		riskLimits: r.Counter("trading_bot_risk_limit_hits_total", "Signals skipped because a risk limit was hit, by limit.", "limit"),
+		errors:     r.Counter("trading_bot_errors_total", "Errors by component.", "component"),
+
+		apiRequests: r.Counter("trading_bot_api_requests_total", "Exchange API requests by path and HTTP status.", "path", "status"),
+		apiErrors:   r.Counter("trading_bot_api_errors_total", "Exchange API requests that failed or returned a non-200 status.", "path"),
+		apiLatency:  r.Histogram("trading_bot_api_request_duration_seconds", "Exchange API request latency.", telemetry.DefaultBuckets, "path"),
+		apiWeight:   r.Gauge("trading_bot_api_used_weight", "Request weight used in the current minute, as last reported by the exchange."),
+
+		equity:    r.Gauge("trading_bot_equity", "Portfolio equity in the reporting currency.", "currency"),
+		cash:      r.Gauge("trading_bot_cash", "Cash balance valued in the reporting currency.", "currency"),
+		positions: r.Gauge("trading_bot_position_quantity", "Quantity held of each non-cash asset.", "asset"),
+		drawdown:  r.Gauge("trading_bot_drawdown_ratio", "Decline of equity from its peak, from 0 to 1."),
+	}
+}
+
+func (t *botTelemetry) observeEvent(envelope events.Envelope) {
+	switch e := envelope.Event.(type) {
+	case events.TickReceived:
+		t.ticks.Inc()
+	case events.SignalGenerated:
+		t.signals.Inc(string(e.Signal.Action))
+	case events.OrderSubmitted:
+		t.orders.Inc("submitted")
+	case events.OrderFilled:
+		t.orders.Inc("filled")
+	case events.OrderRejected:
+		t.orders.Inc("rejected")
+	case events.RiskLimitHit:
+		t.riskLimits.Inc(e.Limit)
+	case events.Error:
+		t.errors.Inc(e.Component)
+	}
+}
+
+func (t *botTelemetry) observeRequest(stats exchange.RequestStats) {
+	t.apiRequests.Inc(stats.Path, strconv.Itoa(stats.Status))
+	t.apiLatency.Observe(stats.Duration.Seconds(), stats.Path)
+	if stats.Err != nil || stats.Status != http.StatusOK {
+		t.apiErrors.Inc(stats.Path)
+	}
+	if stats.UsedWeight > 0 {
+		t.apiWeight.Set(float64(stats.UsedWeight))
+	}
+}
+
+// serve starts the /metrics endpoint. Listening happens before it returns so
+// a bad address fails startup.
+func (t *botTelemetry) serve(address string, onError func(error)) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", t.registry.Handler())
+	t.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := t.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			onError(err)
+		}
+	}()
+	return nil
+}
+
+func (t *botTelemetry) close(ctx context.Context) error {
+	if t.server == nil {
+		return nil
+	}
+	return t.server.Shutdown(ctx)
+}
+
+// observeEquity values the portfolio at the tick's prices, tracking the
+// highest equity seen, including restored samples, for drawdown, and updates
+// the gauges.
+func (bot *TradingBot) observeEquity(currentPrices map[string]float64) {
+	snapshot := bot.portfolio.Snapshot(currentPrices)
+	priced := len(snapshot.Unpriced()) == 0
+	if priced {
+		bot.peakEquity = max(bot.peakEquity, snapshot.Equity)
+	}
+	if bot.telemetry == nil {
+		return
+	}
+
+	t := bot.telemetry
+	positions := make(map[string]float64, len(snapshot.Balances))
+	for asset, quantity := range snapshot.Balances {
+		if asset != snapshot.Cash {
+			positions[asset] = quantity.Float64()
+		}
+	}
+	t.positions.Replace(positions)
+
+	// Valuations keep their last values until every held asset has a price.
+	if !priced {
+		return
+	}
+	t.equity.Set(snapshot.Equity, snapshot.ReportingCurrency)
+	t.cash.Set(reportingCash(snapshot, currentPrices), snapshot.ReportingCurrency)
+	t.drawdown.Set(bot.drawdown(snapshot.Equity))
+}
+
+// drawdown is the decline of equity from its peak, from 0 to 1.
+func (bot *TradingBot) drawdown(equity float64) float64 {
+	if bot.peakEquity <= 0 {
+		return 0
+	}
+	return max(0, (bot.peakEquity-equity)/bot.peakEquity)
+}
@@ -0,0 +1,310 @@
+// Package decimal is a fixed-point decimal type for prices, quantities and
+// balances. Values are stored as an integer count of 10^-8 units, the finest
+// precision Binance quotes, so sums and differences are exact and amounts
+// format back to the exact digits they were parsed from.
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Places is the number of fractional digits a Decimal holds.
+const Places = 8
+
+const scale = 100_000_000
+
+// Decimal is a signed fixed-point number with Places fractional digits. The
+// zero value is 0. Its range is about ±92 billion; arithmetic that would
+// leave it returns ErrOverflow instead of wrapping around.
+type Decimal struct {
+	units int64
+}
+
+var Zero = Decimal{}
+
+// ErrOverflow is returned when a result does not fit in a Decimal.
+var ErrOverflow = errors.New("decimal: value out of range")
+
+// maxFloat bounds the float64 values whose units fit in an int64.
+const maxFloat = math.MaxInt64 / scale
+
+func FromInt(value int64) Decimal {
+	return Decimal{units: value * scale}
+}
+
+// FromFloat converts f, rounding to Places digits. It is meant for values
+// computed in floating point, such as a strategy's order size, and fails for
+// NaN, infinities and values out of range.
+func FromFloat(f float64) (Decimal, error) {
+	if math.IsNaN(f) || math.Abs(f) >= maxFloat {
+		return Zero, fmt.Errorf("%w: %g", ErrOverflow, f)
+	}
+	return Decimal{units: int64(math.Round(f * scale))}, nil
+}
+
+// MustFromFloat is FromFloat for values known to be in range; it panics
+// otherwise.
+func MustFromFloat(f float64) Decimal {
+	d, err := FromFloat(f)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Parse reads a plain decimal string such as "43250.01000000" or "-0.5".
+// Digits beyond Places are accepted only when they are zeros.
+func Parse(s string) (Decimal, error) {
+	text := s
+	negative := false
+	if len(text) > 0 && (text[0] == '-' || text[0] == '+') {
+		negative = text[0] == '-'
+		text = text[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return Zero, fmt.Errorf("invalid decimal %q", s)
+	}
+	if len(fraction) > Places {
+		if strings.Trim(fraction[Places:], "0") != "" {
+			return Zero, fmt.Errorf("invalid decimal %q: more than %d decimal places", s, Places)
+		}
+		fraction = fraction[:Places]
+	}
+
+	digits := whole + fraction + strings.Repeat("0", Places-len(fraction))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Zero, fmt.Errorf("invalid decimal %q", s)
+		}
+	}
+
+	units, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Zero, fmt.Errorf("invalid decimal %q: out of range", s)
+	}
+	if negative {
+		units = -units
+	}
+	return Decimal{units: units}, nil
+}
+
+// MustParse is Parse for constants; it panics on invalid input.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / scale
+}
+
+// String formats d without trailing zeros, e.g. "0.001" or "42".
+func (d Decimal) String() string {
+	s := d.StringFixed(Places)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed formats d with exactly places fractional digits, truncating
+// any beyond them.
+func (d Decimal) StringFixed(places int) string {
+	places = max(0, min(places, Places))
+
+	units := d.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(units)).String()
+	if len(abs) <= Places {
+		abs = strings.Repeat("0", Places-len(abs)+1) + abs
+	}
+
+	whole, fraction := abs[:len(abs)-Places], abs[len(abs)-Places:]
+	if places == 0 {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction[:places]
+}
+
+func (d Decimal) Add(other Decimal) (Decimal, error) {
+	sum := d.units + other.units
+	// Adding two numbers of the same sign overflowed if the sign flipped.
+	if (d.units >= 0) == (other.units >= 0) && (sum >= 0) != (d.units >= 0) {
+		return Zero, fmt.Errorf("%w: %s + %s", ErrOverflow, d, other)
+	}
+	return Decimal{units: sum}, nil
+}
+
+func (d Decimal) Sub(other Decimal) (Decimal, error) {
+	if other.units == math.MinInt64 {
+		return Zero, fmt.Errorf("%w: %s - %s", ErrOverflow, d, other)
+	}
+	return d.Add(Decimal{units: -other.units})
+}
+
+// Mul returns d × other rounded half away from zero to Places digits.
+func (d Decimal) Mul(other Decimal) (Decimal, error) {
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(other.units))
+	result, ok := divRound(product, big.NewInt(scale))
+	if !ok {
+		return Zero, fmt.Errorf("%w: %s × %s", ErrOverflow, d, other)
+	}
+	return result, nil
+}
+
+// Div returns d ÷ other rounded half away from zero to Places digits. It
+// panics if other is zero.
+func (d Decimal) Div(other Decimal) (Decimal, error) {
+	if other.units == 0 {
+		panic("decimal: division by zero")
+	}
+	numerator := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(scale))
+	result, ok := divRound(numerator, big.NewInt(other.units))
+	if !ok {
+		return Zero, fmt.Errorf("%w: %s ÷ %s", ErrOverflow, d, other)
+	}
+	return result, nil
+}
+
+// DivTrunc returns d ÷ other truncated toward zero, so the quotient times
+// other never exceeds d in magnitude. It panics if other is zero.
+func (d Decimal) DivTrunc(other Decimal) (Decimal, error) {
+	if other.units == 0 {
+		panic("decimal: division by zero")
+	}
+	numerator := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(scale))
+	quotient := numerator.Quo(numerator, big.NewInt(other.units))
+	if !quotient.IsInt64() {
+		return Zero, fmt.Errorf("%w: %s ÷ %s", ErrOverflow, d, other)
+	}
+	return Decimal{units: quotient.Int64()}, nil
+}
+
+// Floor rounds d down to a multiple of step, the way an exchange's lot or
+// tick size requires. A zero step leaves d unchanged.
+func (d Decimal) Floor(step Decimal) Decimal {
+	if step.units <= 0 {
+		return d
+	}
+	remainder := d.units % step.units
+	if remainder < 0 {
+		remainder += step.units
+	}
+	return Decimal{units: d.units - remainder}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units}
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.units < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than other.
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.units < other.units:
+		return -1
+	case d.units > other.units:
+		return 1
+	}
+	return 0
+}
+
+func (d Decimal) Sign() int {
+	return d.Cmp(Zero)
+}
+
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+func Min(a, b Decimal) Decimal {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// MarshalJSON writes d as a JSON number with its exact digits.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string, as Binance sends prices
+// and quantities. Numbers in exponent form, written by older float-based
+// records, are rounded to Places digits.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	if strings.ContainsAny(text, "eE") {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("invalid decimal %q", text)
+		}
+		if *d, err = FromFloat(f); err != nil {
+			return fmt.Errorf("invalid decimal %q: %w", text, err)
+		}
+		return nil
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		// Floats marshaled by older records can carry more digits than a
+		// Decimal holds.
+		f, ferr := strconv.ParseFloat(text, 64)
+		if ferr != nil {
+			return err
+		}
+		if parsed, ferr = FromFloat(f); ferr != nil {
+			return err
+		}
+	}
+	*d = parsed
+	return nil
+}
+
+// divRound divides, rounding half away from zero. It reports false when the
+// quotient does not fit in a Decimal.
+func divRound(numerator, denominator *big.Int) (Decimal, bool) {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(denominator)) >= 0 {
+		if (numerator.Sign() < 0) != (denominator.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	if !quotient.IsInt64() {
+		return Zero, false
+	}
+	return Decimal{units: quotient.Int64()}, true
+}
@@ -0,0 +1,216 @@
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"43250.01000000", "43250.01"},
+		{"-0.5", "-0.5"},
+		{"+2", "2"},
+		{".25", "0.25"},
+		{"7.", "7"},
+		{"0.00000001", "0.00000001"},
+		{"1.1234567800", "1.12345678"},
+		{"-0", "0"},
+		{"92233720368.54775807", "92233720368.54775807"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			d, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.in, err)
+			}
+			if got := d.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"-",
+		".",
+		"1.2.3",
+		"1e5",
+		"12a",
+		" 1",
+		"0.123456789",
+		"92233720368.54775808",
+	} {
+		t.Run(in, func(t *testing.T) {
+			if d, err := Parse(in); err == nil {
+				t.Errorf("Parse(%q) = %s, want an error", in, d)
+			}
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want string
+	}{
+		{0.1, "0.1"},
+		{1.000000005, "1.00000001"},
+		{-1.000000005, "-1.00000001"},
+		{0.000000004, "0"},
+		{12345.678, "12345.678"},
+	}
+	for _, tt := range tests {
+		d, err := FromFloat(tt.in)
+		if err != nil {
+			t.Fatalf("FromFloat(%g) failed: %v", tt.in, err)
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("FromFloat(%g) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e11, -1e11} {
+		if _, err := FromFloat(in); !errors.Is(err, ErrOverflow) {
+			t.Errorf("FromFloat(%g) error = %v, want ErrOverflow", in, err)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(a, b Decimal) (Decimal, error)
+		a, b string
+		want string
+	}{
+		{"add", Decimal.Add, "0.1", "0.2", "0.3"},
+		{"sub", Decimal.Sub, "1", "1.00000001", "-0.00000001"},
+		{"mul rounds half up", Decimal.Mul, "0.00000001", "0.5", "0.00000001"},
+		{"mul rounds half away from zero", Decimal.Mul, "-0.00000001", "0.5", "-0.00000001"},
+		{"mul rounds down below half", Decimal.Mul, "0.00000001", "0.49", "0"},
+		{"div rounds", Decimal.Div, "2", "3", "0.66666667"},
+		{"div rounds negative", Decimal.Div, "-2", "3", "-0.66666667"},
+		{"div exact", Decimal.Div, "1000", "43250", "0.02312139"},
+		{"div trunc", Decimal.DivTrunc, "2", "3", "0.66666666"},
+		{"div trunc negative", Decimal.DivTrunc, "-2", "3", "-0.66666666"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op(MustParse(tt.a), MustParse(tt.b))
+			if err != nil {
+				t.Fatalf("%s(%s, %s) failed: %v", tt.name, tt.a, tt.b, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("%s(%s, %s) = %s, want %s", tt.name, tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	largest := Decimal{units: math.MaxInt64}
+	smallest := Decimal{units: math.MinInt64}
+
+	tests := []struct {
+		name string
+		op   func(a, b Decimal) (Decimal, error)
+		a, b Decimal
+	}{
+		{"add", Decimal.Add, largest, MustParse("0.00000001")},
+		{"add negative", Decimal.Add, smallest, MustParse("-0.00000001")},
+		{"sub", Decimal.Sub, smallest, MustParse("0.00000001")},
+		{"sub the minimum", Decimal.Sub, Zero, smallest},
+		{"mul", Decimal.Mul, MustParse("1000000"), MustParse("1000000")},
+		{"div", Decimal.Div, MustParse("1000000"), MustParse("0.00001")},
+		{"div trunc", Decimal.DivTrunc, MustParse("1000000"), MustParse("0.00001")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.op(tt.a, tt.b); !errors.Is(err, ErrOverflow) {
+				t.Errorf("%s(%s, %s) = %s, %v, want ErrOverflow", tt.name, tt.a, tt.b, got, err)
+			}
+		})
+	}
+
+	if got, err := largest.Add(smallest); err != nil || got.String() != "-0.00000001" {
+		t.Errorf("adding opposite signs = %s, %v, want -0.00000001", got, err)
+	}
+}
+
+func TestFloor(t *testing.T) {
+	tests := []struct {
+		d, step, want string
+	}{
+		{"1.23456789", "0.001", "1.234"},
+		{"-1.2345", "0.01", "-1.24"},
+		{"5", "0", "5"},
+		{"0.5", "1", "0"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.d).Floor(MustParse(tt.step)); got.String() != tt.want {
+			t.Errorf("Floor(%s, %s) = %s, want %s", tt.d, tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestStringFixed(t *testing.T) {
+	tests := []struct {
+		d      string
+		places int
+		want   string
+	}{
+		{"1.5", 2, "1.50"},
+		{"1.999", 2, "1.99"},
+		{"-0.001", 2, "-0.00"},
+		{"42", 0, "42"},
+		{"0.00000001", 12, "0.00000001"},
+	}
+	for _, tt := range tests {
+		if got := MustParse(tt.d).StringFixed(tt.places); got != tt.want {
+			t.Errorf("StringFixed(%s, %d) = %s, want %s", tt.d, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`"0.00100000"`, "0.001"},
+		{`1.25`, "1.25"},
+		{`1e-3`, "0.001"},
+		{`0.1234567891`, "0.12345679"},
+	}
+	for _, tt := range tests {
+		var d Decimal
+		if err := json.Unmarshal([]byte(tt.in), &d); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.in, err)
+		}
+		if d.String() != tt.want {
+			t.Errorf("Unmarshal(%s) = %s, want %s", tt.in, d, tt.want)
+		}
+	}
+
+	for _, in := range []string{`"abc"`, `1e300`, `"1e300"`} {
+		var d Decimal
+		if err := json.Unmarshal([]byte(in), &d); err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want an error", in, d)
+		}
+	}
+
+	out, err := json.Marshal(MustParse("-12.50"))
+	if err != nil || string(out) != "-12.5" {
+		t.Errorf("Marshal = %s, %v, want -12.5", out, err)
+	}
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Envelope is a published event with its sequence number and time.
+type Envelope struct {
+	Seq   uint64
+	Time  time.Time
+	Event Event
+}
+
+// Bus fans published events out to subscribers. Publish never blocks: each
+// subscriber has a buffered channel, and events that do not fit are dropped
+// and counted, so a slow subscriber cannot stall trading.
+type Bus struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	seq    uint64
+	closed bool
+	now    func() time.Time
+}
+
+type Subscription struct {
+	bus     *Bus
+	kinds   map[Kind]bool
+	ch      chan Envelope
+	dropped atomic.Uint64
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subs: make(map[*Subscription]struct{}),
+		now:  time.Now,
+	}
+}
+
+// Subscribe receives events of the given kinds, or of every kind when none
+// are given, through a channel holding up to buffer undelivered events.
+func (b *Bus) Subscribe(buffer int, kinds ...Kind) *Subscription {
+	sub := &Subscription{
+		bus: b,
+		ch:  make(chan Envelope, buffer),
+	}
+	if len(kinds) > 0 {
+		sub.kinds = make(map[Kind]bool, len(kinds))
+		for _, kind := range kinds {
+			sub.kinds[kind] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// SubscribeFunc calls handler for each matching event on its own goroutine,
+// in publish order, until the subscription ends.
+func (b *Bus) SubscribeFunc(buffer int, handler func(Envelope), kinds ...Kind) *Subscription {
+	sub := b.Subscribe(buffer, kinds...)
+	go func() {
+		for envelope := range sub.C() {
+			handler(envelope)
+		}
+	}()
+	return sub
+}
+
+func (b *Bus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.seq++
+	envelope := Envelope{Seq: b.seq, Time: b.now(), Event: event}
+	for sub := range b.subs {
+		if sub.kinds != nil && !sub.kinds[event.Kind()] {
+			continue
+		}
+		select {
+		case sub.ch <- envelope:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Close ends every subscription. Publishing afterwards does nothing.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		close(sub.ch)
+	}
+	b.subs = nil
+}
+
+// C delivers the subscription's events and is closed when it ends.
+func (s *Subscription) C() <-chan Envelope {
+	return s.ch
+}
+
+// Dropped counts events discarded because the subscriber fell behind.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *Subscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subs[s]; ok {
+		delete(s.bus.subs, s)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/market"
+	"trading-bot/internal/portfolio"
+	"trading-bot/internal/strategy"
+)
+
+// Kind identifies an event type, e.g. for filtering subscriptions.
+type Kind string
+
+const (
+	KindStarted         Kind = "started"
+	KindStopped         Kind = "stopped"
+	KindTickReceived    Kind = "tick_received"
+	KindSignalGenerated Kind = "signal_generated"
+	KindOrderSubmitted  Kind = "order_submitted"
+	KindOrderFilled     Kind = "order_filled"
+	KindOrderRejected   Kind = "order_rejected"
+	KindRiskLimitHit    Kind = "risk_limit_hit"
+	KindError           Kind = "error"
+)
+
+// Event is one of the event types below.
+type Event interface {
+	Kind() Kind
+}
+
+type Started struct {
+	Symbol   string
+	Strategy string
+	DryRun   bool
+}
+
+type Stopped struct {
+	Symbol string
+}
+
+type TickReceived struct {
+	Data market.Data
+}
+
+// SignalGenerated is published for every non-hold signal, before risk checks
+// decide whether it is acted on.
+type SignalGenerated struct {
+	Strategy string
+	Signal   strategy.Signal
+}
+
+// OrderSubmitted is an order the exchange accepted.
+type OrderSubmitted struct {
+	Order exchange.OrderResponse
+}
+
+// OrderFilled is a fill booked into the portfolio, published for each
+// execution the exchange reports. Partial fills publish one event each.
+type OrderFilled struct {
+	Transaction portfolio.Transaction
+	DryRun      bool
+}
+
+// OrderRejected is an order the exchange or the portfolio refused.
+type OrderRejected struct {
+	Symbol   string
+	Side     exchange.Side
+	Quantity decimal.Decimal
+	Price    decimal.Decimal
+	Reason   string
+}
+
+// RiskLimitHit is a signal that was not acted on because a limit stood in
+// the way, such as insufficient balance or a halt.
+type RiskLimitHit struct {
+	Limit  string
+	Action strategy.Action
+	Detail string
+}
+
+type Error struct {
+	Component string
+	Err       error
+}
+
+func (Started) Kind() Kind         { return KindStarted }
+func (Stopped) Kind() Kind         { return KindStopped }
+func (TickReceived) Kind() Kind    { return KindTickReceived }
+func (SignalGenerated) Kind() Kind { return KindSignalGenerated }
+func (OrderSubmitted) Kind() Kind  { return KindOrderSubmitted }
+func (OrderFilled) Kind() Kind     { return KindOrderFilled }
+func (OrderRejected) Kind() Kind   { return KindOrderRejected }
+func (RiskLimitHit) Kind() Kind    { return KindRiskLimitHit }
+func (Error) Kind() Kind           { return KindError }
@@ -1,18 +1,23 @@
 package exchange
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"trading-bot/internal/decimal"
 	"trading-bot/internal/market"
 )
 
@@ -21,6 +26,33 @@ type BinanceClient struct {
 	SecretKey  string
 	BaseURL    string
 	HTTPClient *http.Client
+	Logger     *slog.Logger
+
+	// OnRequest, if set, is called after every API request, e.g. to export
+	// latency and rate limit usage.
+	OnRequest func(RequestStats)
+
+	mu      sync.Mutex
+	filters map[string]SymbolFilters
+}
+
+// SymbolFilters are the increments and minimum Binance accepts for a
+// symbol's order quantities and prices.
+type SymbolFilters struct {
+	StepSize decimal.Decimal
+	MinQty   decimal.Decimal
+	TickSize decimal.Decimal
+}
+
+// RequestStats describes one API request. Status is zero when no response
+// arrived, and UsedWeight is zero when the response did not report it.
+type RequestStats struct {
+	Method     string
+	Path       string
+	Status     int
+	Duration   time.Duration
+	UsedWeight int
+	Err        error
 }
 
 type BinanceTicker struct {
@@ -39,22 +71,19 @@ func NewBinanceClient(apiKey, secretKey string, testNet bool) (*BinanceClient, e
 		SecretKey:  secretKey,
 		BaseURL:    baseURL,
 		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Logger:     slog.Default().With("component", "exchange"),
+		filters:    make(map[string]SymbolFilters),
 	}, nil
 }
 
-func (bc *BinanceClient) GetMarketData(symbol string) (*market.Data, error) {
+func (bc *BinanceClient) GetMarketData(ctx context.Context, symbol string) (*market.Data, error) {
 	endpoint := "/api/v3/ticker/price"
 	params := url.Values{}
 	params.Add("symbol", symbol)
 
 	url := fmt.Sprintf("%s%s?%s", bc.BaseURL, endpoint, params.Encode())
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := bc.HTTPClient.Do(req)
+	resp, err := bc.get(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +99,7 @@ func (bc *BinanceClient) GetMarketData(symbol string) (*market.Data, error) {
 		return nil, err
 	}
 
-	price, err := strconv.ParseFloat(ticker.Price, 64)
+	price, err := decimal.Parse(ticker.Price)
 	if err != nil {
 		return nil, err
 	}
@@ -83,20 +112,289 @@ func (bc *BinanceClient) GetMarketData(symbol string) (*market.Data, error) {
 	}, nil
 }
 
-func (bc *BinanceClient) PlaceOrder(symbol string, side Side, orderType OrderType, quantity, price float64) (*OrderResponse, error) {
-	endpoint := "/api/v3/order"
+func (bc *BinanceClient) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]market.Candle, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("interval", interval)
+	if limit > 0 {
+		params.Add("limit", strconv.Itoa(limit))
+	}
+
+	return bc.fetchKlines(ctx, symbol, params)
+}
+
+// GetKlinesRange pages through klines between start and end, which may span
+// more than the 1000 klines Binance returns per request.
+func (bc *BinanceClient) GetKlinesRange(ctx context.Context, symbol, interval string, start, end time.Time) ([]market.Candle, error) {
+	candles := make([]market.Candle, 0)
+
+	for start.Before(end) {
+		params := url.Values{}
+		params.Add("symbol", symbol)
+		params.Add("interval", interval)
+		params.Add("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+		params.Add("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+		params.Add("limit", "1000")
+
+		page, err := bc.fetchKlines(ctx, symbol, params)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		candles = append(candles, page...)
+		start = page[len(page)-1].CloseTime.Add(time.Millisecond)
+	}
+
+	return candles, nil
+}
+
+func (bc *BinanceClient) fetchKlines(ctx context.Context, symbol string, params url.Values) ([]market.Candle, error) {
+	endpoint := "/api/v3/klines"
+	url := fmt.Sprintf("%s%s?%s", bc.BaseURL, endpoint, params.Encode())
+
+	resp, err := bc.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("binance API error: %s", string(body))
+	}
+
+	var rows [][]json.RawMessage
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, err
+	}
+
+	candles := make([]market.Candle, 0, len(rows))
+	for _, row := range rows {
+		candle, err := parseKline(symbol, row)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, candle)
+	}
+
+	return candles, nil
+}
+
+// parseKline decodes one kline row: open time, open, high, low, close,
+// volume and close time, followed by fields the bot does not use.
+func parseKline(symbol string, row []json.RawMessage) (market.Candle, error) {
+	if len(row) < 7 {
+		return market.Candle{}, fmt.Errorf("malformed kline: %d fields", len(row))
+	}
+
+	var openTime, closeTime int64
+	if err := json.Unmarshal(row[0], &openTime); err != nil {
+		return market.Candle{}, fmt.Errorf("malformed kline open time: %w", err)
+	}
+	if err := json.Unmarshal(row[6], &closeTime); err != nil {
+		return market.Candle{}, fmt.Errorf("malformed kline close time: %w", err)
+	}
+
+	values := make([]float64, 5)
+	for i := range values {
+		var raw string
+		if err := json.Unmarshal(row[i+1], &raw); err != nil {
+			return market.Candle{}, fmt.Errorf("malformed kline value: %w", err)
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return market.Candle{}, fmt.Errorf("malformed kline value: %w", err)
+		}
+		values[i] = value
+	}
+
+	return market.Candle{
+		Symbol:    symbol,
+		OpenTime:  time.UnixMilli(openTime),
+		CloseTime: time.UnixMilli(closeTime),
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+		Volume:    values[4],
+	}, nil
+}
+
+func (bc *BinanceClient) PlaceOrder(ctx context.Context, symbol string, side Side, orderType OrderType, quantity, price decimal.Decimal) (*OrderResponse, error) {
+	filters, err := bc.SymbolFilters(ctx, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %s filters: %w", symbol, err)
+	}
+
+	quantity = quantity.Floor(filters.StepSize)
+	if quantity.Cmp(filters.MinQty) < 0 || quantity.Sign() <= 0 {
+		return nil, fmt.Errorf("order quantity %s is below the %s minimum of %s", quantity, symbol, filters.MinQty)
+	}
 
 	params := url.Values{}
 	params.Add("symbol", symbol)
 	params.Add("side", string(side))
 	params.Add("type", string(orderType))
-	params.Add("quantity", fmt.Sprintf("%.8f", quantity))
+	params.Add("quantity", quantity.String())
 
 	if orderType == TypeLimit {
-		params.Add("price", fmt.Sprintf("%.8f", price))
+		params.Add("price", price.Floor(filters.TickSize).String())
 		params.Add("timeInForce", "GTC")
 	}
 
+	body, err := bc.signedRequest(ctx, "POST", "/api/v3/order", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderResp OrderResponse
+	if err := json.Unmarshal(body, &orderResp); err != nil {
+		return nil, err
+	}
+
+	bc.Logger.Info("Order placed", "symbol", symbol, "order_id", orderResp.OrderID, "side", side, "type", orderType,
+		"quantity", orderResp.Quantity, "price", orderResp.Price, "status", orderResp.Status)
+	return &orderResp, nil
+}
+
+// SymbolFilters returns the symbol's LOT_SIZE and PRICE_FILTER values,
+// fetching them from exchangeInfo once and caching them.
+func (bc *BinanceClient) SymbolFilters(ctx context.Context, symbol string) (SymbolFilters, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if filters, ok := bc.filters[symbol]; ok {
+		return filters, nil
+	}
+
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	resp, err := bc.get(ctx, fmt.Sprintf("%s/api/v3/exchangeInfo?%s", bc.BaseURL, params.Encode()))
+	if err != nil {
+		return SymbolFilters{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return SymbolFilters{}, err
+	}
+	if resp.StatusCode != 200 {
+		return SymbolFilters{}, fmt.Errorf("binance API error: %s", string(body))
+	}
+
+	var info struct {
+		Symbols []struct {
+			Symbol  string `json:"symbol"`
+			Filters []struct {
+				FilterType string          `json:"filterType"`
+				StepSize   decimal.Decimal `json:"stepSize"`
+				MinQty     decimal.Decimal `json:"minQty"`
+				TickSize   decimal.Decimal `json:"tickSize"`
+			} `json:"filters"`
+		} `json:"symbols"`
+	}
+	if err := json.Unmarshal(body, &info); err != nil {
+		return SymbolFilters{}, err
+	}
+
+	for _, s := range info.Symbols {
+		if s.Symbol != symbol {
+			continue
+		}
+		var filters SymbolFilters
+		for _, f := range s.Filters {
+			switch f.FilterType {
+			case "LOT_SIZE":
+				filters.StepSize, filters.MinQty = f.StepSize, f.MinQty
+			case "PRICE_FILTER":
+				filters.TickSize = f.TickSize
+			}
+		}
+		bc.filters[symbol] = filters
+		return filters, nil
+	}
+	return SymbolFilters{}, fmt.Errorf("symbol %s not listed", symbol)
+}
+
+func (bc *BinanceClient) CancelOrder(ctx context.Context, symbol string, orderID int64) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("orderId", strconv.FormatInt(orderID, 10))
+
+	body, err := bc.signedRequest(ctx, "DELETE", "/api/v3/order", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderResp OrderResponse
+	if err := json.Unmarshal(body, &orderResp); err != nil {
+		return nil, err
+	}
+
+	bc.Logger.Info("Order cancelled", "symbol", symbol, "order_id", orderID, "status", orderResp.Status)
+	return &orderResp, nil
+}
+
+func (bc *BinanceClient) GetOrder(ctx context.Context, symbol string, orderID int64) (*OrderResponse, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("orderId", strconv.FormatInt(orderID, 10))
+
+	body, err := bc.signedRequest(ctx, "GET", "/api/v3/order", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var order OrderResponse
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (bc *BinanceClient) GetOpenOrders(ctx context.Context, symbol string) ([]OrderResponse, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+
+	body, err := bc.signedRequest(ctx, "GET", "/api/v3/openOrders", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []OrderResponse
+	if err := json.Unmarshal(body, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (bc *BinanceClient) GetAccount(ctx context.Context) (*Account, error) {
+	body, err := bc.signedRequest(ctx, "GET", "/api/v3/account", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var account Account
+	if err := json.Unmarshal(body, &account); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// signedRequest sends an authenticated request. GET and DELETE parameters
+// travel in the query string, everything else in a form-encoded body.
+func (bc *BinanceClient) signedRequest(ctx context.Context, method, endpoint string, params url.Values) ([]byte, error) {
 	params.Add("timestamp", strconv.FormatInt(time.Now().Unix()*1000, 10))
 
 	signature := bc.generateSignature(params.Encode())
@@ -104,16 +402,23 @@ func (bc *BinanceClient) PlaceOrder(symbol string, side Side, orderType OrderTyp
 
 	url := fmt.Sprintf("%s%s", bc.BaseURL, endpoint)
 
-	req, err := http.NewRequest("POST", url, nil)
+	var req *http.Request
+	var err error
+	if method == "GET" || method == "DELETE" {
+		req, err = http.NewRequestWithContext(ctx, method, url+"?"+params.Encode(), nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, url, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("X-MBX-APIKEY", bc.APIKey)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Body = io.NopCloser(strings.NewReader(params.Encode()))
 
-	resp, err := bc.HTTPClient.Do(req)
+	resp, err := bc.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -128,19 +433,14 @@ func (bc *BinanceClient) PlaceOrder(symbol string, side Side, orderType OrderTyp
 		return nil, fmt.Errorf("binance API error: %s", string(body))
 	}
 
-	var orderResp OrderResponse
-	if err := json.Unmarshal(body, &orderResp); err != nil {
-		return nil, err
-	}
-
-	return &orderResp, nil
+	return body, nil
 }
 
-func (bc *BinanceClient) TestConnection() error {
+func (bc *BinanceClient) TestConnection(ctx context.Context) error {
 	endpoint := "/api/v3/ping"
 	url := fmt.Sprintf("%s%s", bc.BaseURL, endpoint)
 
-	resp, err := bc.HTTPClient.Get(url)
+	resp, err := bc.get(ctx, url)
 	if err != nil {
 		return err
 	}
@@ -153,6 +453,47 @@ func (bc *BinanceClient) TestConnection() error {
 	return nil
 }
 
+// get sends an unauthenticated GET bound to ctx.
+func (bc *BinanceClient) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bc.do(req)
+}
+
+// do sends req and logs its outcome. Only the path is logged, so query
+// strings carrying signatures stay out of the logs.
+func (bc *BinanceClient) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := bc.HTTPClient.Do(req)
+	elapsed := time.Since(start)
+
+	switch {
+	case err != nil:
+		cause := err
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			cause = urlErr.Err
+		}
+		bc.Logger.Warn("API request failed", "method", req.Method, "path", req.URL.Path, "duration", elapsed, "error", cause)
+	case resp.StatusCode != http.StatusOK:
+		bc.Logger.Warn("API request rejected", "method", req.Method, "path", req.URL.Path, "status", resp.StatusCode, "duration", elapsed)
+	default:
+		bc.Logger.Debug("API request", "method", req.Method, "path", req.URL.Path, "status", resp.StatusCode, "duration", elapsed)
+	}
+
+	if bc.OnRequest != nil {
+		stats := RequestStats{Method: req.Method, Path: req.URL.Path, Duration: elapsed, Err: err}
+		if resp != nil {
+			stats.Status = resp.StatusCode
+			stats.UsedWeight, _ = strconv.Atoi(resp.Header.Get("X-Mbx-Used-Weight-1m"))
+		}
+		bc.OnRequest(stats)
+	}
+	return resp, err
+}
+
 func (bc *BinanceClient) generateSignature(queryString string) string {
 	h := hmac.New(sha256.New, []byte(bc.SecretKey))
 	h.Write([]byte(queryString))
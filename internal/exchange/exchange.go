@@ -1,6 +1,10 @@
 package exchange
 
 import (
+	"context"
+	"fmt"
+
+	"trading-bot/internal/decimal"
 	"trading-bot/internal/market"
 )
 
@@ -17,19 +21,68 @@ const (
 	TypeLimit  OrderType = "LIMIT"
 )
 
+// OrderResponse is an order as the exchange reports it. ExecutedQty is how
+// much has traded so far and CumulativeQuoteQty what it traded for, so
+// together they give the average fill price.
 type OrderResponse struct {
-	Symbol        string `json:"symbol"`
-	OrderID       int64  `json:"orderId"`
-	ClientOrderID string `json:"clientOrderId"`
-	Status        string `json:"status"`
-	Type          string `json:"type"`
-	Side          string `json:"side"`
-	Quantity      string `json:"origQty"`
-	Price         string `json:"price"`
+	Symbol             string          `json:"symbol"`
+	OrderID            int64           `json:"orderId"`
+	ClientOrderID      string          `json:"clientOrderId"`
+	Status             string          `json:"status"`
+	Type               string          `json:"type"`
+	Side               string          `json:"side"`
+	Quantity           decimal.Decimal `json:"origQty"`
+	Price              decimal.Decimal `json:"price"`
+	ExecutedQty        decimal.Decimal `json:"executedQty"`
+	CumulativeQuoteQty decimal.Decimal `json:"cummulativeQuoteQty"`
+	Time               int64           `json:"time"`
+	TransactTime       int64           `json:"transactTime"`
+}
+
+// Open reports whether the order is still on the book and may trade more.
+func (o OrderResponse) Open() bool {
+	return o.Status == "NEW" || o.Status == "PARTIALLY_FILLED"
+}
+
+type Balance struct {
+	Asset  string          `json:"asset"`
+	Free   decimal.Decimal `json:"free"`
+	Locked decimal.Decimal `json:"locked"`
+}
+
+type Account struct {
+	Balances   []Balance `json:"balances"`
+	UpdateTime int64     `json:"updateTime"`
+}
+
+// Total returns the free plus locked balance of an asset, or zero when the
+// account does not hold it.
+func (a *Account) Total(asset string) (decimal.Decimal, error) {
+	for _, b := range a.Balances {
+		if b.Asset == asset {
+			total, err := b.Free.Add(b.Locked)
+			if err != nil {
+				return decimal.Zero, fmt.Errorf("%s balance: %w", asset, err)
+			}
+			return total, nil
+		}
+	}
+	return decimal.Zero, nil
 }
 
+// Exchange is a trading venue. Every call is bound to ctx and returns early
+// with ctx's error once it is cancelled.
 type Exchange interface {
-	GetMarketData(symbol string) (*market.Data, error)
-	PlaceOrder(symbol string, side Side, orderType OrderType, quantity, price float64) (*OrderResponse, error)
-	TestConnection() error
+	GetMarketData(ctx context.Context, symbol string) (*market.Data, error)
+	GetKlines(ctx context.Context, symbol, interval string, limit int) ([]market.Candle, error)
+	// PlaceOrder rounds quantity and price down to the symbol's lot and tick
+	// sizes; the response carries the quantity actually ordered.
+	PlaceOrder(ctx context.Context, symbol string, side Side, orderType OrderType, quantity, price decimal.Decimal) (*OrderResponse, error)
+	CancelOrder(ctx context.Context, symbol string, orderID int64) (*OrderResponse, error)
+	// GetOrder reports an order in any state, including filled and cancelled
+	// orders that no longer appear among the open ones.
+	GetOrder(ctx context.Context, symbol string, orderID int64) (*OrderResponse, error)
+	GetOpenOrders(ctx context.Context, symbol string) ([]OrderResponse, error)
+	GetAccount(ctx context.Context) (*Account, error)
+	TestConnection(ctx context.Context) error
 }
@@ -0,0 +1,128 @@
+package indicator
+
+import (
+	"math"
+	"slices"
+)
+
+func SMA(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period {
+		return 0
+	}
+
+	sum := 0.0
+	for _, v := range values[len(values)-period:] {
+		sum += v
+	}
+
+	return sum / float64(period)
+}
+
+func EMA(values []float64, period int) float64 {
+	series := EMASeries(values, period)
+	if len(series) == 0 {
+		return 0
+	}
+	return series[len(series)-1]
+}
+
+// EMASeries seeds the average with the SMA of the first period values, so the
+// returned slice is aligned with values[period-1:].
+func EMASeries(values []float64, period int) []float64 {
+	if period <= 0 || len(values) < period {
+		return nil
+	}
+
+	k := 2.0 / float64(period+1)
+	series := make([]float64, 0, len(values)-period+1)
+	ema := SMA(values[:period], period)
+	series = append(series, ema)
+
+	for _, v := range values[period:] {
+		ema = (v-ema)*k + ema
+		series = append(series, ema)
+	}
+
+	return series
+}
+
+func StdDev(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period {
+		return 0
+	}
+
+	mean := SMA(values, period)
+	variance := 0.0
+	for _, v := range values[len(values)-period:] {
+		variance += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(variance / float64(period))
+}
+
+func BollingerBands(values []float64, period int, multiplier float64) (upper, middle, lower float64) {
+	middle = SMA(values, period)
+	deviation := StdDev(values, period) * multiplier
+	return middle + deviation, middle, middle - deviation
+}
+
+// RSI uses simple averages of the gains and losses over the last period
+// price changes.
+func RSI(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period+1 {
+		return 50.0
+	}
+
+	avgGain := 0.0
+	avgLoss := 0.0
+
+	for i := len(values) - period; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		if change > 0 {
+			avgGain += change
+		} else {
+			avgLoss -= change
+		}
+	}
+
+	avgGain /= float64(period)
+	avgLoss /= float64(period)
+
+	if avgLoss == 0 {
+		return 100.0
+	}
+
+	rs := avgGain / avgLoss
+	return 100.0 - (100.0 / (1.0 + rs))
+}
+
+// MACD returns the latest MACD line and signal line values.
+func MACD(values []float64, fastPeriod, slowPeriod, signalPeriod int) (macd, signal float64) {
+	fast := EMASeries(values, fastPeriod)
+	slow := EMASeries(values, slowPeriod)
+	if len(slow) == 0 || len(fast) < len(slow) {
+		return 0, 0
+	}
+	fast = fast[len(fast)-len(slow):]
+
+	line := make([]float64, len(slow))
+	for i := range slow {
+		line[i] = fast[i] - slow[i]
+	}
+
+	return line[len(line)-1], EMA(line, signalPeriod)
+}
+
+func Highest(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period {
+		return 0
+	}
+	return slices.Max(values[len(values)-period:])
+}
+
+func Lowest(values []float64, period int) float64 {
+	if period <= 0 || len(values) < period {
+		return 0
+	}
+	return slices.Min(values[len(values)-period:])
+}
@@ -0,0 +1,225 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/portfolio"
+)
+
+type Kind string
+
+const (
+	KindGenesis     Kind = "genesis"
+	KindTransaction Kind = "transaction"
+	KindOrder       Kind = "order"
+)
+
+// Record is one journal entry. Exactly one payload is set, matching Kind;
+// the genesis record, always first, carries the starting balances and cash
+// asset. Journals written before balances were per asset only have
+// InitialBalance.
+type Record struct {
+	Seq            uint64                     `json:"seq"`
+	Time           time.Time                  `json:"time"`
+	Kind           Kind                       `json:"kind"`
+	InitialBalance float64                    `json:"initial_balance,omitempty"`
+	Balances       map[string]decimal.Decimal `json:"balances,omitempty"`
+	Cash           string                     `json:"cash,omitempty"`
+	Transaction    *portfolio.Transaction     `json:"transaction,omitempty"`
+	Order          *exchange.OrderResponse    `json:"order,omitempty"`
+}
+
+// Journal is an append-only log of records, one per line, each written as
+// the hex CRC-32 of its JSON followed by a space and the JSON. Every append
+// is fsynced before it returns.
+type Journal struct {
+	mu        sync.Mutex
+	file      *os.File
+	seq       uint64
+	truncated int64
+}
+
+// Open reads an existing journal, or creates an empty one, and returns its
+// records. A torn or corrupt final line, left by a crash mid-write, is cut
+// off; corruption anywhere else is an error.
+func Open(filename string) (*Journal, []Record, error) {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("error creating journal directory: %w", err)
+	}
+
+	_, statErr := os.Stat(filename)
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening journal: %w", err)
+	}
+	if os.IsNotExist(statErr) {
+		// Make the new file's directory entry durable too.
+		if err := syncDir(dir); err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+	}
+
+	records, valid, err := readRecords(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	j := &Journal{file: file}
+	if len(records) > 0 {
+		j.seq = records[len(records)-1].Seq
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("error reading journal: %w", err)
+	}
+	if info.Size() > valid {
+		j.truncated = info.Size() - valid
+		if err := file.Truncate(valid); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("error recovering journal: %w", err)
+		}
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("error recovering journal: %w", err)
+		}
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("error opening journal: %w", err)
+	}
+
+	return j, records, nil
+}
+
+// readRecords returns the records and the length of the valid prefix.
+func readRecords(file *os.File) ([]Record, int64, error) {
+	reader := bufio.NewReader(file)
+	records := make([]Record, 0)
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Anything after the last newline is a torn write.
+			return records, offset, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("error reading journal: %w", err)
+		}
+
+		record, parseErr := parseLine(line)
+		if parseErr == nil && len(records) > 0 && record.Seq != records[len(records)-1].Seq+1 {
+			parseErr = fmt.Errorf("sequence %d follows %d", record.Seq, records[len(records)-1].Seq)
+		}
+		if parseErr != nil {
+			if _, err := reader.Peek(1); err == io.EOF {
+				return records, offset, nil
+			}
+			return nil, 0, fmt.Errorf("corrupt journal record at offset %d: %w", offset, parseErr)
+		}
+
+		records = append(records, record)
+		offset += int64(len(line))
+	}
+}
+
+func parseLine(line []byte) (Record, error) {
+	var record Record
+
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	sum, payload, ok := bytes.Cut(line, []byte(" "))
+	if !ok {
+		return record, fmt.Errorf("missing checksum")
+	}
+
+	var expected uint32
+	if _, err := fmt.Sscanf(string(sum), "%08x", &expected); err != nil {
+		return record, fmt.Errorf("invalid checksum: %w", err)
+	}
+	if crc32.ChecksumIEEE(payload) != expected {
+		return record, fmt.Errorf("checksum mismatch")
+	}
+
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+// Truncated returns how many bytes of a torn final record Open discarded.
+func (j *Journal) Truncated() int64 {
+	return j.truncated
+}
+
+func (j *Journal) AppendGenesis(balances map[string]decimal.Decimal, cash string) error {
+	return j.append(Record{Kind: KindGenesis, Balances: balances, Cash: cash})
+}
+
+func (j *Journal) AppendTransaction(t portfolio.Transaction) error {
+	return j.append(Record{Time: t.Timestamp, Kind: KindTransaction, Transaction: &t})
+}
+
+func (j *Journal) AppendOrder(order exchange.OrderResponse) error {
+	return j.append(Record{Kind: KindOrder, Order: &order})
+}
+
+func (j *Journal) append(record Record) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	record.Seq = j.seq + 1
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding journal record: %w", err)
+	}
+	line := fmt.Appendf(nil, "%08x %s\n", crc32.ChecksumIEEE(payload), payload)
+
+	if _, err := j.file.Write(line); err != nil {
+		return fmt.Errorf("error writing journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing journal: %w", err)
+	}
+
+	j.seq = record.Seq
+	return nil
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("error syncing journal directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("error syncing journal directory: %w", err)
+	}
+	return nil
+}
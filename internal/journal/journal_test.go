@@ -0,0 +1,206 @@
+package journal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/portfolio"
+)
+
+// writeJournal appends a genesis record and one buy to a new journal and
+// returns its path.
+func writeJournal(t *testing.T) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "journal.log")
+
+	j, records, err := Open(filename)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("new journal has %d records", len(records))
+	}
+	if err := j.AppendGenesis(map[string]decimal.Decimal{"USDT": decimal.MustParse("1000")}, "USDT"); err != nil {
+		t.Fatalf("AppendGenesis failed: %v", err)
+	}
+	buy := portfolio.Transaction{
+		Type:   "BUY",
+		Symbol: "BTC",
+		Quote:  "USDT",
+		Amount: decimal.MustParse("0.01"),
+		Price:  decimal.MustParse("50000"),
+		Total:  decimal.MustParse("500"),
+	}
+	if err := j.AppendTransaction(buy); err != nil {
+		t.Fatalf("AppendTransaction failed: %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return filename
+}
+
+func appendBytes(t *testing.T, filename string, data []byte) {
+	t.Helper()
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenReadsRecords(t *testing.T) {
+	filename := writeJournal(t)
+
+	j, records, err := Open(filename)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer j.Close()
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0].Kind != KindGenesis || records[1].Kind != KindTransaction {
+		t.Errorf("kinds = %s, %s, want genesis, transaction", records[0].Kind, records[1].Kind)
+	}
+	if j.Truncated() != 0 {
+		t.Errorf("Truncated() = %d, want 0", j.Truncated())
+	}
+
+	// Appends continue the sequence.
+	if err := j.AppendOrder(exchange.OrderResponse{OrderID: 7, Status: "NEW"}); err != nil {
+		t.Fatalf("AppendOrder failed: %v", err)
+	}
+	j.Close()
+	if _, records, err = Open(filename); err != nil || len(records) != 3 || records[2].Seq != 3 {
+		t.Fatalf("reopened journal has %d records, err %v", len(records), err)
+	}
+}
+
+func TestOpenCutsTornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{"partial line", `1234abcd {"seq":3,"kind":"tra`},
+		{"checksum mismatch", "00000000 {\"seq\":3,\"kind\":\"order\"}\n"},
+		{"missing checksum", "{\"seq\":3}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := writeJournal(t)
+			before, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			appendBytes(t, filename, []byte(tt.tail))
+
+			j, records, err := Open(filename)
+			if err != nil {
+				t.Fatalf("Open failed: %v", err)
+			}
+			if len(records) != 2 {
+				t.Errorf("got %d records, want 2", len(records))
+			}
+			if got := j.Truncated(); got != int64(len(tt.tail)) {
+				t.Errorf("Truncated() = %d, want %d", got, len(tt.tail))
+			}
+
+			// The next append starts on a clean line.
+			if err := j.AppendOrder(exchange.OrderResponse{OrderID: 1, Status: "NEW"}); err != nil {
+				t.Fatalf("AppendOrder failed: %v", err)
+			}
+			j.Close()
+
+			after, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(after, before) {
+				t.Errorf("recovery changed the valid records")
+			}
+			if _, records, err := Open(filename); err != nil || len(records) != 3 {
+				t.Errorf("reopened journal has %d records, err %v", len(records), err)
+			}
+		})
+	}
+}
+
+func TestOpenRejectsCorruptionBeforeTail(t *testing.T) {
+	filename := writeJournal(t)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip a byte in the first record's payload.
+	corrupt := bytes.Replace(data, []byte(`"genesis"`), []byte(`"genesiz"`), 1)
+	if err := os.WriteFile(filename, corrupt, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Open(filename); err == nil {
+		t.Fatal("Open succeeded on a journal corrupt before its last record")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	buy := portfolio.Transaction{
+		Type:   "BUY",
+		Symbol: "BTC",
+		Quote:  "USDT",
+		Amount: decimal.MustParse("0.01"),
+		Price:  decimal.MustParse("50000"),
+		Total:  decimal.MustParse("500"),
+	}
+	records := []Record{
+		{Kind: KindGenesis, Balances: map[string]decimal.Decimal{"USDT": decimal.MustParse("1000")}, Cash: "USDT"},
+		{Kind: KindTransaction, Transaction: &buy},
+		{Kind: KindOrder, Order: &exchange.OrderResponse{OrderID: 1, Status: "NEW"}},
+		{Kind: KindOrder, Order: &exchange.OrderResponse{OrderID: 2, Status: "NEW"}},
+		{Kind: KindOrder, Order: &exchange.OrderResponse{OrderID: 1, Status: "FILLED"}},
+		{Kind: "equity"},
+	}
+
+	state, err := Replay(records)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(state.OpenOrders) != 1 || state.OpenOrders[0].OrderID != 2 {
+		t.Errorf("open orders = %+v, want only order 2", state.OpenOrders)
+	}
+
+	p, err := state.Restore(portfolio.CostFIFO)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if got := p.GetBalance().String(); got != "500" {
+		t.Errorf("cash = %s, want 500", got)
+	}
+	if got := p.GetPosition("BTC").String(); got != "0.01" {
+		t.Errorf("position = %s, want 0.01", got)
+	}
+}
+
+func TestReplayLegacyGenesis(t *testing.T) {
+	state, err := Replay([]Record{{Kind: KindGenesis, InitialBalance: 2500}})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if state.Cash != portfolio.DefaultCash || state.Balances[portfolio.DefaultCash].String() != "2500" {
+		t.Errorf("legacy genesis = %s %v, want 2500 %s", state.Cash, state.Balances, portfolio.DefaultCash)
+	}
+
+	if _, err := Replay([]Record{{Kind: KindGenesis, InitialBalance: 1e300}}); err == nil {
+		t.Error("Replay accepted a genesis balance out of range")
+	}
+}
@@ -0,0 +1,73 @@
+package journal
+
+import (
+	"fmt"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/exchange"
+	"trading-bot/internal/portfolio"
+)
+
+// State is what a journal's records add up to.
+type State struct {
+	Balances     map[string]decimal.Decimal
+	Cash         string
+	Transactions []portfolio.Transaction
+	OpenOrders   []exchange.OrderResponse
+}
+
+// Replay folds records into a State. An order's latest record decides
+// whether it is still open. Records of other kinds, such as the equity
+// samples older journals carry, are skipped.
+func Replay(records []Record) (State, error) {
+	var state State
+	orders := make(map[int64]exchange.OrderResponse)
+	orderIDs := make([]int64, 0)
+
+	for _, record := range records {
+		switch record.Kind {
+		case KindGenesis:
+			state.Balances, state.Cash = record.Balances, record.Cash
+			if state.Cash == "" {
+				balance, err := decimal.FromFloat(record.InitialBalance)
+				if err != nil {
+					return State{}, fmt.Errorf("invalid genesis balance: %w", err)
+				}
+				state.Cash = portfolio.DefaultCash
+				state.Balances = map[string]decimal.Decimal{state.Cash: balance}
+			}
+		case KindTransaction:
+			if record.Transaction != nil {
+				state.Transactions = append(state.Transactions, *record.Transaction)
+			}
+		case KindOrder:
+			if record.Order == nil {
+				continue
+			}
+			if _, seen := orders[record.Order.OrderID]; !seen {
+				orderIDs = append(orderIDs, record.Order.OrderID)
+			}
+			orders[record.Order.OrderID] = *record.Order
+		}
+	}
+
+	for _, id := range orderIDs {
+		if order := orders[id]; order.Open() {
+			state.OpenOrders = append(state.OpenOrders, order)
+		}
+	}
+
+	return state, nil
+}
+
+// Restore rebuilds a portfolio from the journaled transactions.
+func (s State) Restore(method portfolio.CostMethod) (*portfolio.Portfolio, error) {
+	p := portfolio.NewPortfolioWithBalances(s.Balances, s.Cash)
+	p.SetCostMethod(method)
+	for i, t := range s.Transactions {
+		if err := p.Replay(t); err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", i+1, err)
+		}
+	}
+	return p, nil
+}
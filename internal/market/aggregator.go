@@ -0,0 +1,110 @@
+package market
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Aggregator builds bars for several timeframes from the same tick or candle
+// stream. Only closed bars are ever exposed, so every timeframe's view is
+// consistent with the latest input and free of look-ahead.
+type Aggregator struct {
+	builders map[string]*CandleBuilder
+	bars     map[string][]Candle
+	maxBars  int
+}
+
+func NewAggregator(intervals []string, maxBars int) (*Aggregator, error) {
+	agg := &Aggregator{
+		builders: make(map[string]*CandleBuilder),
+		bars:     make(map[string][]Candle),
+		maxBars:  maxBars,
+	}
+
+	for _, interval := range intervals {
+		duration, err := ParseInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+		agg.builders[interval] = NewCandleBuilder(duration)
+		agg.bars[interval] = make([]Candle, 0)
+	}
+
+	return agg, nil
+}
+
+func (a *Aggregator) Intervals() []string {
+	intervals := make([]string, 0, len(a.builders))
+	for interval := range a.builders {
+		intervals = append(intervals, interval)
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return a.builders[intervals[i]].Interval() < a.builders[intervals[j]].Interval()
+	})
+	return intervals
+}
+
+func (a *Aggregator) AddTick(data *Data) {
+	for interval, builder := range a.builders {
+		if candle, closed := builder.Add(data); closed {
+			a.appendBar(interval, *candle)
+		}
+	}
+}
+
+// AddCandle feeds a base candle, e.g. a 1m kline during a backtest. Timeframes
+// shorter than the candle cannot be built from it and are left untouched.
+func (a *Aggregator) AddCandle(candle *Candle) {
+	span := candle.CloseTime.Sub(candle.OpenTime) + time.Millisecond
+	for interval, builder := range a.builders {
+		if builder.Interval() < span {
+			continue
+		}
+		for _, closed := range builder.AddCandle(candle) {
+			a.appendBar(interval, closed)
+		}
+	}
+}
+
+// Seed preloads closed history for one timeframe, e.g. klines fetched at
+// startup. Candles that had not closed by now are dropped.
+func (a *Aggregator) Seed(interval string, candles []Candle, now time.Time) error {
+	if _, ok := a.builders[interval]; !ok {
+		return fmt.Errorf("aggregator has no %s timeframe", interval)
+	}
+
+	for _, candle := range candles {
+		if candle.CloseTime.Before(now) {
+			a.appendBar(interval, candle)
+		}
+	}
+	return nil
+}
+
+func (a *Aggregator) Bars(interval string) []Candle {
+	bars := make([]Candle, len(a.bars[interval]))
+	copy(bars, a.bars[interval])
+	return bars
+}
+
+func (a *Aggregator) Snapshot() map[string][]Candle {
+	snapshot := make(map[string][]Candle, len(a.bars))
+	for interval := range a.bars {
+		snapshot[interval] = a.Bars(interval)
+	}
+	return snapshot
+}
+
+func (a *Aggregator) appendBar(interval string, candle Candle) {
+	bars := a.bars[interval]
+	if len(bars) > 0 && !candle.OpenTime.After(bars[len(bars)-1].OpenTime) {
+		return
+	}
+
+	bars = append(bars, candle)
+	if len(bars) > a.maxBars {
+		bars = bars[len(bars)-a.maxBars:]
+	}
+	a.bars[interval] = bars
+}
@@ -0,0 +1,165 @@
+package market
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Candle is a bar of an analytics series. Its prices are float64 since they
+// feed indicator math; the prices the bot trades at come from Data.
+type Candle struct {
+	Symbol    string    `json:"symbol"`
+	OpenTime  time.Time `json:"open_time"`
+	CloseTime time.Time `json:"close_time"`
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`
+	Volume    float64   `json:"volume"`
+}
+
+func ParseInterval(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid interval: %q", interval)
+	}
+
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid interval: %q", interval)
+	}
+
+	switch interval[len(interval)-1] {
+	case 's':
+		return time.Duration(n) * time.Second, nil
+	case 'm':
+		return time.Duration(n) * time.Minute, nil
+	case 'h':
+		return time.Duration(n) * time.Hour, nil
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, nil
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, nil
+	}
+
+	return 0, fmt.Errorf("invalid interval: %q", interval)
+}
+
+// klineIntervals are the intervals Binance serves klines for, longest first.
+var klineIntervals = []string{"1w", "3d", "1d", "12h", "8h", "6h", "4h", "2h", "1h", "30m", "15m", "5m", "3m", "1m", "1s"}
+
+// KlineSource returns the longest kline interval that divides interval
+// evenly, so its klines can be resampled into bars of interval. It is false
+// for intervals that are not whole seconds.
+func KlineSource(interval time.Duration) (string, time.Duration, bool) {
+	for _, name := range klineIntervals {
+		d, _ := ParseInterval(name)
+		if interval >= d && interval%d == 0 {
+			return name, d, true
+		}
+	}
+	return "", 0, false
+}
+
+// Resample folds consecutive candles into bars of interval, dropping the
+// partial bars the candles start or end in the middle of.
+func Resample(candles []Candle, interval time.Duration) []Candle {
+	for len(candles) > 0 && !candles[0].OpenTime.Equal(candles[0].OpenTime.Truncate(interval)) {
+		candles = candles[1:]
+	}
+
+	builder := NewCandleBuilder(interval)
+	bars := make([]Candle, 0, len(candles))
+	for i := range candles {
+		bars = append(bars, builder.AddCandle(&candles[i])...)
+	}
+	return bars
+}
+
+type CandleBuilder struct {
+	interval time.Duration
+	current  *Candle
+}
+
+func NewCandleBuilder(interval time.Duration) *CandleBuilder {
+	return &CandleBuilder{interval: interval}
+}
+
+func (cb *CandleBuilder) Interval() time.Duration {
+	return cb.interval
+}
+
+// Add folds a tick into the candle for its interval. When the tick belongs to
+// a later interval the previous candle is complete and is returned.
+func (cb *CandleBuilder) Add(data *Data) (*Candle, bool) {
+	openTime := data.Timestamp.Truncate(cb.interval)
+	price := data.Price.Float64()
+
+	if cb.current != nil && !openTime.After(cb.current.OpenTime) {
+		cb.current.High = max(cb.current.High, price)
+		cb.current.Low = min(cb.current.Low, price)
+		cb.current.Close = price
+		cb.current.Volume += data.Volume
+		return nil, false
+	}
+
+	closed := cb.current
+	cb.current = &Candle{
+		Symbol:    data.Symbol,
+		OpenTime:  openTime,
+		CloseTime: openTime.Add(cb.interval - time.Millisecond),
+		Open:      price,
+		High:      price,
+		Low:       price,
+		Close:     price,
+		Volume:    data.Volume,
+	}
+
+	return closed, closed != nil
+}
+
+// AddCandle folds a shorter-interval candle into this builder's interval. A
+// candle is returned as soon as the candle completing its interval arrives,
+// so replayed history never exposes a bar before it has closed.
+func (cb *CandleBuilder) AddCandle(candle *Candle) []Candle {
+	closed := make([]Candle, 0, 1)
+	openTime := candle.OpenTime.Truncate(cb.interval)
+
+	if cb.current != nil && openTime.After(cb.current.OpenTime) {
+		closed = append(closed, *cb.current)
+		cb.current = nil
+	}
+
+	if cb.current == nil {
+		cb.current = &Candle{
+			Symbol:    candle.Symbol,
+			OpenTime:  openTime,
+			CloseTime: openTime.Add(cb.interval - time.Millisecond),
+			Open:      candle.Open,
+			High:      candle.High,
+			Low:       candle.Low,
+			Close:     candle.Close,
+			Volume:    candle.Volume,
+		}
+	} else {
+		cb.current.High = max(cb.current.High, candle.High)
+		cb.current.Low = min(cb.current.Low, candle.Low)
+		cb.current.Close = candle.Close
+		cb.current.Volume += candle.Volume
+	}
+
+	if !candle.CloseTime.Before(cb.current.CloseTime) {
+		closed = append(closed, *cb.current)
+		cb.current = nil
+	}
+
+	return closed
+}
+
+func (cb *CandleBuilder) Current() *Candle {
+	if cb.current == nil {
+		return nil
+	}
+	candle := *cb.current
+	return &candle
+}
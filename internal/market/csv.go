@@ -0,0 +1,84 @@
+package market
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+var candleCSVHeader = []string{"open_time", "close_time", "open", "high", "low", "close", "volume"}
+
+// LoadCandlesCSV reads candles written by SaveCandlesCSV. Times are Unix
+// milliseconds, matching Binance kline exports.
+func LoadCandlesCSV(filename, symbol string) ([]Candle, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening candle file: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading candle file: %w", err)
+	}
+
+	candles := make([]Candle, 0, len(rows))
+	for i, row := range rows {
+		if i == 0 && len(row) > 0 && row[0] == candleCSVHeader[0] {
+			continue
+		}
+		if len(row) < len(candleCSVHeader) {
+			return nil, fmt.Errorf("line %d: expected %d fields, found %d", i+1, len(candleCSVHeader), len(row))
+		}
+
+		values := make([]float64, len(candleCSVHeader))
+		for j := range candleCSVHeader {
+			if values[j], err = strconv.ParseFloat(row[j], 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s: %w", i+1, candleCSVHeader[j], err)
+			}
+		}
+
+		candles = append(candles, Candle{
+			Symbol:    symbol,
+			OpenTime:  time.UnixMilli(int64(values[0])),
+			CloseTime: time.UnixMilli(int64(values[1])),
+			Open:      values[2],
+			High:      values[3],
+			Low:       values[4],
+			Close:     values[5],
+			Volume:    values[6],
+		})
+	}
+
+	return candles, nil
+}
+
+func SaveCandlesCSV(filename string, candles []Candle) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating candle file: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write(candleCSVHeader)
+	for _, c := range candles {
+		w.Write([]string{
+			strconv.FormatInt(c.OpenTime.UnixMilli(), 10),
+			strconv.FormatInt(c.CloseTime.UnixMilli(), 10),
+			strconv.FormatFloat(c.Open, 'f', -1, 64),
+			strconv.FormatFloat(c.High, 'f', -1, 64),
+			strconv.FormatFloat(c.Low, 'f', -1, 64),
+			strconv.FormatFloat(c.Close, 'f', -1, 64),
+			strconv.FormatFloat(c.Volume, 'f', -1, 64),
+		})
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("error writing candle file: %w", err)
+	}
+	return file.Close()
+}
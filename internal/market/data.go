@@ -1,18 +1,21 @@
 package market
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"math/rand"
 	"net/http"
 	"time"
+
+	"trading-bot/internal/decimal"
 )
 
 type Data struct {
-	Symbol    string    `json:"symbol"`
-	Price     float64   `json:"price"`
-	Volume    float64   `json:"volume"`
-	Timestamp time.Time `json:"timestamp"`
+	Symbol    string          `json:"symbol"`
+	Price     decimal.Decimal `json:"price"`
+	Volume    float64         `json:"volume"`
+	Timestamp time.Time       `json:"timestamp"`
 }
 
 type CoinGeckoResponse struct {
@@ -21,19 +24,29 @@ type CoinGeckoResponse struct {
 	} `json:"bitcoin"`
 }
 
-func FetchMockData(symbol string) (*Data, error) {
+// FetchMockData returns a dry-run tick. Bitcoin uses CoinGecko's live price
+// when reachable; a cancelled ctx returns its error rather than mock data.
+func FetchMockData(ctx context.Context, symbol string) (*Data, error) {
 	if symbol == "BTC/USD" || symbol == "BTCUSDT" {
-		return fetchBTCPrice()
+		return fetchBTCPrice(ctx)
 	}
 	return generateMockData(symbol), nil
 }
 
-func fetchBTCPrice() (*Data, error) {
+func fetchBTCPrice(ctx context.Context) (*Data, error) {
 	url := "https://api.coingecko.com/api/v3/simple/price?ids=bitcoin&vs_currencies=usd"
 
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	resp, err := client.Do(req)
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return generateMockData("BTC/USD"), nil
 	}
 	defer resp.Body.Close()
@@ -51,11 +64,14 @@ func fetchBTCPrice() (*Data, error) {
 	if err := json.Unmarshal(body, &cgResp); err != nil {
 		return generateMockData("BTC/USD"), nil
 	}
+	price, err := decimal.FromFloat(cgResp.Bitcoin.USD)
+	if err != nil {
+		return generateMockData("BTC/USD"), nil
+	}
 
 	return &Data{
 		Symbol:    "BTC/USD",
-		Price:     cgResp.Bitcoin.USD,
-		Volume:    rand.Float64() * 1000000,
+		Price:     price,
 		Timestamp: time.Now(),
 	}, nil
 }
@@ -66,16 +82,14 @@ func generateMockData(symbol string) *Data {
 		variation := (rand.Float64() - 0.5) * 2000
 		return &Data{
 			Symbol:    symbol,
-			Price:     basePrice + variation,
-			Volume:    rand.Float64() * 1000000,
+			Price:     decimal.MustFromFloat(basePrice + variation),
 			Timestamp: time.Now(),
 		}
 	}
 
 	return &Data{
 		Symbol:    symbol,
-		Price:     1000.0 + (rand.Float64()-0.5)*100,
-		Volume:    rand.Float64() * 100000,
+		Price:     decimal.MustFromFloat(1000.0 + (rand.Float64()-0.5)*100),
 		Timestamp: time.Now(),
 	}
 }
@@ -0,0 +1,51 @@
+package market
+
+import "strings"
+
+type History struct {
+	ticks   []Data
+	maxSize int
+}
+
+func NewHistory(maxSize int) *History {
+	return &History{
+		ticks:   make([]Data, 0),
+		maxSize: maxSize,
+	}
+}
+
+func (h *History) Add(data *Data) {
+	h.ticks = append(h.ticks, *data)
+	if len(h.ticks) > h.maxSize {
+		h.ticks = h.ticks[len(h.ticks)-h.maxSize:]
+	}
+}
+
+func (h *History) Len() int {
+	return len(h.ticks)
+}
+
+func (h *History) Ticks() []Data {
+	ticks := make([]Data, len(h.ticks))
+	copy(ticks, h.ticks)
+	return ticks
+}
+
+var quoteAssets = []string{"USDT", "BUSD", "USDC", "FDUSD", "TUSD", "USD", "EUR", "BTC", "ETH", "BNB"}
+
+// SplitSymbol splits an exchange symbol such as BTCUSDT or BTC/USD into its
+// base and quote assets. Symbols with an unknown quote asset are returned
+// whole as the base.
+func SplitSymbol(symbol string) (base, quote string) {
+	if base, quote, ok := strings.Cut(symbol, "/"); ok {
+		return base, quote
+	}
+
+	for _, q := range quoteAssets {
+		if len(symbol) > len(q) && strings.HasSuffix(symbol, q) {
+			return strings.TrimSuffix(symbol, q), q
+		}
+	}
+
+	return symbol, ""
+}
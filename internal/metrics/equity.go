@@ -0,0 +1,231 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Sample is one observation of the account. Exposure is the share of equity
+// held in positions.
+type Sample struct {
+	Time          time.Time `json:"time"`
+	Equity        float64   `json:"equity"`
+	Cash          float64   `json:"cash"`
+	PositionValue float64   `json:"position_value"`
+	Exposure      float64   `json:"exposure"`
+}
+
+func NewSample(t time.Time, equity, cash float64) Sample {
+	s := Sample{Time: t, Equity: equity, Cash: cash, PositionValue: equity - cash}
+	if equity > 0 {
+		s.Exposure = s.PositionValue / equity
+	}
+	return s
+}
+
+// Curve keeps the most recent samples of an equity curve in memory.
+type Curve struct {
+	samples    []Sample
+	maxSamples int
+}
+
+func NewCurve(maxSamples int) *Curve {
+	return &Curve{
+		samples:    make([]Sample, 0),
+		maxSamples: maxSamples,
+	}
+}
+
+func (c *Curve) Record(s Sample) {
+	c.samples = append(c.samples, s)
+	if c.maxSamples > 0 && len(c.samples) > c.maxSamples {
+		c.samples = c.samples[len(c.samples)-c.maxSamples:]
+	}
+}
+
+func (c *Curve) Len() int {
+	return len(c.samples)
+}
+
+func (c *Curve) Last() (Sample, bool) {
+	if len(c.samples) == 0 {
+		return Sample{}, false
+	}
+	return c.samples[len(c.samples)-1], true
+}
+
+func (c *Curve) Samples() []Sample {
+	samples := make([]Sample, len(c.samples))
+	copy(samples, c.samples)
+	return samples
+}
+
+func (c *Curve) Points() []EquityPoint {
+	points := make([]EquityPoint, len(c.samples))
+	for i, s := range c.samples {
+		points[i] = EquityPoint{Time: s.Time, Value: s.Equity}
+	}
+	return points
+}
+
+var sampleCSVHeader = []string{"time", "equity", "cash", "position_value", "exposure"}
+
+func sampleRow(s Sample) []string {
+	return []string{
+		s.Time.UTC().Format(time.RFC3339),
+		strconv.FormatFloat(s.Equity, 'f', 2, 64),
+		strconv.FormatFloat(s.Cash, 'f', 2, 64),
+		strconv.FormatFloat(s.PositionValue, 'f', 2, 64),
+		strconv.FormatFloat(s.Exposure, 'f', 4, 64),
+	}
+}
+
+func WriteSamplesCSV(w io.Writer, samples []Sample) error {
+	cw := csv.NewWriter(w)
+	cw.Write(sampleCSVHeader)
+	for _, s := range samples {
+		cw.Write(sampleRow(s))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func SaveSamplesCSV(filename string, samples []Sample) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating equity file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteSamplesCSV(file, samples); err != nil {
+		return fmt.Errorf("error writing equity file: %w", err)
+	}
+	return file.Close()
+}
+
+// LoadSamplesCSV reads samples written by SaveSamplesCSV or
+// AppendSampleCSV. A malformed final line, left by a crash mid-append, is
+// skipped.
+func LoadSamplesCSV(filename string) ([]Sample, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening equity file: %w", err)
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading equity file: %w", err)
+	}
+
+	samples := make([]Sample, 0, len(rows))
+	for i, row := range rows {
+		if i == 0 && len(row) > 0 && row[0] == sampleCSVHeader[0] {
+			continue
+		}
+		sample, err := parseSampleRow(row)
+		if err != nil {
+			if i == len(rows)-1 {
+				break
+			}
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		samples = append(samples, sample)
+	}
+
+	return samples, nil
+}
+
+func parseSampleRow(row []string) (Sample, error) {
+	if len(row) != len(sampleCSVHeader) {
+		return Sample{}, fmt.Errorf("expected %d fields, found %d", len(sampleCSVHeader), len(row))
+	}
+
+	t, err := time.Parse(time.RFC3339, row[0])
+	if err != nil {
+		return Sample{}, fmt.Errorf("invalid time: %w", err)
+	}
+	values := make([]float64, len(row)-1)
+	for j := range values {
+		if values[j], err = strconv.ParseFloat(row[j+1], 64); err != nil {
+			return Sample{}, fmt.Errorf("invalid %s: %w", sampleCSVHeader[j+1], err)
+		}
+	}
+
+	return Sample{Time: t, Equity: values[0], Cash: values[1], PositionValue: values[2], Exposure: values[3]}, nil
+}
+
+// AppendSampleCSV adds one sample to a CSV file, writing the header first
+// when the file is new, so a running bot can stream its curve to disk. A torn
+// last line, left by a crash mid-append, is cut off first so it cannot be
+// glued to the new row, and the row is fsynced before it returns.
+func AppendSampleCSV(filename string, s Sample) error {
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creating equity directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening equity file: %w", err)
+	}
+	defer file.Close()
+
+	valid, err := completeLines(file)
+	if err != nil {
+		return fmt.Errorf("error reading equity file: %w", err)
+	}
+	if err := file.Truncate(valid); err != nil {
+		return fmt.Errorf("error recovering equity file: %w", err)
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		return fmt.Errorf("error recovering equity file: %w", err)
+	}
+
+	cw := csv.NewWriter(file)
+	if valid == 0 {
+		cw.Write(sampleCSVHeader)
+	}
+	cw.Write(sampleRow(s))
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing equity file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("error syncing equity file: %w", err)
+	}
+	return file.Close()
+}
+
+// completeLines returns the length of the file up to and including its last
+// newline.
+func completeLines(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 4096)
+	end := info.Size()
+	for end > 0 {
+		start := max(0, end-int64(len(buf)))
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendSampleCSVRecoversTornLine(t *testing.T) {
+	tests := []struct {
+		name string
+		torn string
+	}{
+		{"torn row", "2026-01-01T00:02:00Z,10050.5,50"},
+		{"torn header", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "equity.csv")
+			start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			var want int
+			if tt.torn == "" {
+				// The crash hit while the header of a new file was written.
+				if err := os.WriteFile(filename, []byte("time,equ"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				for i := range 2 {
+					if err := AppendSampleCSV(filename, NewSample(start.Add(time.Duration(i)*time.Minute), 10000, 5000)); err != nil {
+						t.Fatalf("AppendSampleCSV failed: %v", err)
+					}
+				}
+				want = 2
+				file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				file.WriteString(tt.torn)
+				file.Close()
+			}
+
+			next := NewSample(start.Add(3*time.Minute), 10100, 5000)
+			if err := AppendSampleCSV(filename, next); err != nil {
+				t.Fatalf("AppendSampleCSV after a torn line failed: %v", err)
+			}
+			want++
+
+			samples, err := LoadSamplesCSV(filename)
+			if err != nil {
+				t.Fatalf("LoadSamplesCSV failed: %v", err)
+			}
+			if len(samples) != want {
+				t.Fatalf("loaded %d samples, want %d", len(samples), want)
+			}
+			if last := samples[len(samples)-1]; !last.Time.Equal(next.Time) || last.Equity != next.Equity {
+				t.Errorf("last sample = %+v, want %+v", last, next)
+			}
+		})
+	}
+}
+
+func TestLoadSamplesCSVSkipsTornLastLine(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "equity.csv")
+	if err := AppendSampleCSV(filename, NewSample(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 10000, 5000)); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("2026-01-01T00:01:00Z,100")
+	file.Close()
+
+	samples, err := LoadSamplesCSV(filename)
+	if err != nil || len(samples) != 1 {
+		t.Errorf("LoadSamplesCSV = %d samples, %v, want 1 sample", len(samples), err)
+	}
+}
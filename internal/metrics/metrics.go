@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/portfolio"
+)
+
+type EquityPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// Report summarizes trading performance. Trade statistics count every sell
+// as a closed trade, using the realized P&L the portfolio recorded for it.
+// Risk statistics come from the equity curve and are zero without one.
+type Report struct {
+	RealizedPnL   float64
+	UnrealizedPnL float64
+	TotalPnL      float64
+
+	TradeCount   int
+	ClosedTrades int
+	Wins         int
+	Losses       int
+	WinRate      float64
+	AverageWin   float64
+	AverageLoss  float64
+	ProfitFactor float64
+	Expectancy   float64
+
+	Period      time.Duration
+	MaxDrawdown float64
+	Sharpe      float64
+	Sortino     float64
+	Calmar      float64
+	Exposure    float64
+}
+
+// Compute builds a report from the transaction history, an equity curve
+// sampled in time order and the current prices, in the reporting currency,
+// used to value open positions. Realized P&L is in the trades' quote assets;
+// unrealized P&L converts cost bases at the quote asset's price when prices
+// has one.
+func Compute(trades []portfolio.Transaction, equity []EquityPoint, prices map[string]float64) Report {
+	var r Report
+
+	quantity := make(map[string]decimal.Decimal)
+	cost := make(map[string]decimal.Decimal)
+	quotes := make(map[string]string)
+	grossProfit, grossLoss := 0.0, 0.0
+
+	for _, t := range trades {
+		if t.Quote != "" {
+			quotes[t.Symbol] = t.Quote
+		}
+
+		switch t.Type {
+		case "BUY":
+			r.TradeCount++
+			quantity[t.Symbol] = add(quantity[t.Symbol], t.Amount)
+			cost[t.Symbol] = add(cost[t.Symbol], t.Total)
+		case "SELL":
+			r.TradeCount++
+			quantity[t.Symbol] = add(quantity[t.Symbol], t.Amount.Neg())
+			cost[t.Symbol] = add(cost[t.Symbol], t.CostBasis.Neg())
+
+			pnl := t.RealizedPnL.Float64()
+			r.ClosedTrades++
+			r.RealizedPnL += pnl
+			if pnl > 0 {
+				r.Wins++
+				grossProfit += pnl
+			} else if pnl < 0 {
+				r.Losses++
+				grossLoss -= pnl
+			}
+		case "ADJUST":
+			// Reconciliation adjustments change holdings without being trades.
+			if t.Symbol == "" {
+				continue
+			}
+			if t.Amount.Sign() > 0 {
+				cost[t.Symbol] = add(cost[t.Symbol], mul(t.Amount, t.Price))
+			} else if quantity[t.Symbol].Sign() > 0 {
+				removed, _ := mul(t.Amount, cost[t.Symbol]).Div(quantity[t.Symbol])
+				cost[t.Symbol] = add(cost[t.Symbol], removed)
+			}
+			quantity[t.Symbol] = add(quantity[t.Symbol], t.Amount)
+		}
+	}
+
+	for symbol, held := range quantity {
+		if price, ok := prices[symbol]; ok && held.Sign() > 0 {
+			rate, ok := prices[quotes[symbol]]
+			if !ok {
+				rate = 1
+			}
+			r.UnrealizedPnL += held.Float64()*price - cost[symbol].Float64()*rate
+		}
+	}
+	r.TotalPnL = r.RealizedPnL + r.UnrealizedPnL
+
+	if r.ClosedTrades > 0 {
+		r.WinRate = float64(r.Wins) / float64(r.ClosedTrades)
+		r.Expectancy = r.RealizedPnL / float64(r.ClosedTrades)
+	}
+	if r.Wins > 0 {
+		r.AverageWin = grossProfit / float64(r.Wins)
+	}
+	if r.Losses > 0 {
+		r.AverageLoss = grossLoss / float64(r.Losses)
+	}
+	r.ProfitFactor = profitFactor(grossProfit, grossLoss)
+
+	if len(equity) > 1 {
+		r.Period = equity[len(equity)-1].Time.Sub(equity[0].Time)
+		r.MaxDrawdown = MaxDrawdown(equity)
+		r.Sharpe = SharpeRatio(equity)
+		r.Sortino = SortinoRatio(equity)
+		r.Calmar = CalmarRatio(equity)
+		r.Exposure = exposure(trades, equity)
+	}
+
+	return r
+}
+
+// MaxProfitFactor caps the profit factor, which has no bound when few or no
+// trades lost, so that rankings such as the optimizer's stay meaningful.
+const MaxProfitFactor = 100.0
+
+// profitFactor is MaxProfitFactor when there were winning trades and no
+// losing ones.
+func profitFactor(grossProfit, grossLoss float64) float64 {
+	if grossLoss == 0 {
+		if grossProfit > 0 {
+			return MaxProfitFactor
+		}
+		return 0
+	}
+	return min(grossProfit/grossLoss, MaxProfitFactor)
+}
+
+// add and mul rebuild amounts from booked transactions. Their running totals
+// are balances and cost bases the portfolio already checked fit in a
+// Decimal, so they cannot overflow.
+func add(a, b decimal.Decimal) decimal.Decimal {
+	sum, _ := a.Add(b)
+	return sum
+}
+
+func mul(a, b decimal.Decimal) decimal.Decimal {
+	product, _ := a.Mul(b)
+	return product
+}
+
+// exposure returns the fraction of the equity curve's span during which any
+// position was held.
+func exposure(trades []portfolio.Transaction, equity []EquityPoint) float64 {
+	span := equity[len(equity)-1].Time.Sub(equity[0].Time)
+	if span <= 0 {
+		return 0
+	}
+
+	quantity := make(map[string]decimal.Decimal)
+	next := 0
+	var held time.Duration
+
+	for i := 1; i < len(equity); i++ {
+		for next < len(trades) && !trades[next].Timestamp.After(equity[i-1].Time) {
+			t := trades[next]
+			switch t.Type {
+			case "BUY", "ADJUST":
+				quantity[t.Symbol] = add(quantity[t.Symbol], t.Amount)
+			case "SELL":
+				quantity[t.Symbol] = add(quantity[t.Symbol], t.Amount.Neg())
+			}
+			next++
+		}
+
+		for _, q := range quantity {
+			if q.Sign() > 0 {
+				held += equity[i].Time.Sub(equity[i-1].Time)
+				break
+			}
+		}
+	}
+
+	return float64(held) / float64(span)
+}
+
+// Print writes the report with amounts in currency, the asset the books
+// report in.
+func (r Report) Print(w io.Writer, currency string) {
+	fmt.Fprintf(w, "Realized P&L: %s\n", portfolio.FormatAmount(r.RealizedPnL, currency))
+	fmt.Fprintf(w, "Unrealized P&L: %s\n", portfolio.FormatAmount(r.UnrealizedPnL, currency))
+	fmt.Fprintf(w, "Trades: %d (%d closed, %d wins, %d losses)\n", r.TradeCount, r.ClosedTrades, r.Wins, r.Losses)
+	if r.ClosedTrades > 0 {
+		fmt.Fprintf(w, "Win Rate: %.2f%%\n", r.WinRate*100)
+		fmt.Fprintf(w, "Average Win/Loss: %s / %s\n", portfolio.FormatAmount(r.AverageWin, currency), portfolio.FormatAmount(r.AverageLoss, currency))
+		fmt.Fprintf(w, "Profit Factor: %.2f\n", r.ProfitFactor)
+		fmt.Fprintf(w, "Expectancy: %s per trade\n", portfolio.FormatAmount(r.Expectancy, currency))
+	}
+	if r.Period > 0 {
+		fmt.Fprintf(w, "Max Drawdown: %.2f%%\n", r.MaxDrawdown*100)
+		fmt.Fprintf(w, "Sharpe / Sortino / Calmar: %.2f / %.2f / %.2f\n", r.Sharpe, r.Sortino, r.Calmar)
+		fmt.Fprintf(w, "Exposure: %.2f%% of %s\n", r.Exposure*100, r.Period.Round(time.Second))
+	}
+}
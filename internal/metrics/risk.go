@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// MaxDrawdown returns the largest peak-to-trough decline as a fraction of
+// the peak.
+func MaxDrawdown(equity []EquityPoint) float64 {
+	peak, drawdown := 0.0, 0.0
+	for _, point := range equity {
+		peak = max(peak, point.Value)
+		if peak > 0 {
+			drawdown = max(drawdown, (peak-point.Value)/peak)
+		}
+	}
+	return drawdown
+}
+
+// SharpeRatio annualizes the mean per-bar return over its standard deviation,
+// assuming a zero risk-free rate. The bar length is the median spacing of the
+// equity samples.
+func SharpeRatio(equity []EquityPoint) float64 {
+	returns, barsPerYear := barReturns(equity)
+	mean, std := MeanStdDev(returns)
+	if std == 0 || barsPerYear == 0 {
+		return 0
+	}
+	return mean / std * math.Sqrt(barsPerYear)
+}
+
+// SortinoRatio is the Sharpe ratio with only below-zero returns counted as
+// risk.
+func SortinoRatio(equity []EquityPoint) float64 {
+	returns, barsPerYear := barReturns(equity)
+	if len(returns) == 0 || barsPerYear == 0 {
+		return 0
+	}
+
+	mean, downside := 0.0, 0.0
+	for _, r := range returns {
+		mean += r
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	mean /= float64(len(returns))
+	downside = math.Sqrt(downside / float64(len(returns)))
+	if downside == 0 {
+		return 0
+	}
+	return mean / downside * math.Sqrt(barsPerYear)
+}
+
+// CalmarRatio divides the compound annual growth rate over the curve by its
+// maximum drawdown.
+func CalmarRatio(equity []EquityPoint) float64 {
+	if len(equity) < 2 || equity[0].Value <= 0 {
+		return 0
+	}
+	drawdown := MaxDrawdown(equity)
+	span := equity[len(equity)-1].Time.Sub(equity[0].Time)
+	if drawdown == 0 || span <= 0 {
+		return 0
+	}
+
+	years := float64(span) / float64(365*24*time.Hour)
+	growth := math.Pow(equity[len(equity)-1].Value/equity[0].Value, 1/years) - 1
+	return growth / drawdown
+}
+
+// barReturns returns the per-sample returns and how many samples of the
+// median spacing fit in a year.
+func barReturns(equity []EquityPoint) ([]float64, float64) {
+	if len(equity) < 3 {
+		return nil, 0
+	}
+
+	returns := make([]float64, 0, len(equity)-1)
+	spacings := make([]time.Duration, 0, len(equity)-1)
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1].Value > 0 {
+			returns = append(returns, equity[i].Value/equity[i-1].Value-1)
+		}
+		spacings = append(spacings, equity[i].Time.Sub(equity[i-1].Time))
+	}
+
+	sort.Slice(spacings, func(i, j int) bool { return spacings[i] < spacings[j] })
+	bar := spacings[len(spacings)/2]
+	if bar <= 0 {
+		return returns, 0
+	}
+	return returns, float64(365*24*time.Hour) / float64(bar)
+}
+
+// MeanStdDev returns the mean and population standard deviation of values,
+// or zeros when there are none.
+func MeanStdDev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
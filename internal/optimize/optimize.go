@@ -0,0 +1,204 @@
+package optimize
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"trading-bot/internal/backtest"
+	"trading-bot/internal/bot"
+	"trading-bot/internal/market"
+	"trading-bot/internal/portfolio"
+)
+
+type Objective string
+
+const (
+	ObjectiveSharpe       Objective = "sharpe"
+	ObjectiveNetProfit    Objective = "net_profit"
+	ObjectiveMaxDrawdown  Objective = "max_drawdown"
+	ObjectiveProfitFactor Objective = "profit_factor"
+)
+
+// objectives score a result so that higher is always better.
+var objectives = map[Objective]func(r *backtest.Result) float64{
+	ObjectiveSharpe:       func(r *backtest.Result) float64 { return r.Sharpe },
+	ObjectiveNetProfit:    func(r *backtest.Result) float64 { return r.NetProfit },
+	ObjectiveMaxDrawdown:  func(r *backtest.Result) float64 { return -r.MaxDrawdown },
+	ObjectiveProfitFactor: func(r *backtest.Result) float64 { return r.ProfitFactor },
+}
+
+func (o Objective) Score(r *backtest.Result) float64 {
+	return objectives[o](r)
+}
+
+type Trial struct {
+	Params ParamSet
+	Result *backtest.Result
+	Score  float64
+	Err    error
+}
+
+// Run backtests every candidate of spec on candles across workers goroutines
+// (all CPUs when workers <= 0) and returns the trials ranked best first.
+// Trials that failed, e.g. because a combination is invalid, sort last.
+func Run(spec *Spec, base *bot.Config, candles []market.Candle, workers int) []Trial {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	candidates := spec.Candidates()
+	trials := make([]Trial, len(candidates))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				trials[i] = runTrial(spec, base, candles, candidates[i])
+			}
+		}()
+	}
+
+	for i := range candidates {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	Rank(trials)
+	return trials
+}
+
+func Rank(trials []Trial) {
+	sort.SliceStable(trials, func(i, j int) bool {
+		if (trials[i].Err == nil) != (trials[j].Err == nil) {
+			return trials[i].Err == nil
+		}
+		return trials[i].Score > trials[j].Score
+	})
+}
+
+func runTrial(spec *Spec, base *bot.Config, candles []market.Candle, params ParamSet) Trial {
+	trial := Trial{Params: params, Score: math.Inf(-1)}
+
+	result, err := runBacktest(spec, base, params, candles, nil)
+	if err != nil {
+		trial.Err = err
+		return trial
+	}
+
+	trial.Result = result
+	trial.Score = spec.Objective.Score(result)
+	return trial
+}
+
+func runBacktest(spec *Spec, base *bot.Config, params ParamSet, candles, warmUp []market.Candle) (*backtest.Result, error) {
+	config, err := params.Apply(base)
+	if err != nil {
+		return nil, err
+	}
+
+	name := spec.Strategy
+	if name == "" {
+		name = config.Trading.Strategy
+	}
+	config.Trading.Strategy = name
+	config.Bot.DryRun = true
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	strat, err := bot.NewStrategy(name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return backtest.Run(strat, candles, backtest.Config{
+		Symbol:         config.Trading.Symbol,
+		InitialBalance: config.Trading.InitialBalance,
+		CostMethod:     config.CostMethod(),
+		WarmUp:         warmUp,
+	})
+}
+
+var resultColumns = []string{"score", "net_profit", "return_pct", "max_drawdown", "sharpe", "profit_factor", "trades", "error"}
+
+func WriteCSV(w io.Writer, spec *Spec, trials []Trial) error {
+	paths := make([]string, len(spec.Params))
+	for i, p := range spec.Params {
+		paths[i] = p.Path
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(append(append([]string{"rank"}, paths...), resultColumns...))
+
+	for i, trial := range trials {
+		row := []string{strconv.Itoa(i + 1)}
+		for _, path := range paths {
+			row = append(row, fmt.Sprint(trial.Params[path]))
+		}
+
+		if trial.Err != nil {
+			row = append(row, "", "", "", "", "", "", "", trial.Err.Error())
+		} else {
+			r := trial.Result
+			row = append(row,
+				formatFloat(trial.Score),
+				formatFloat(r.NetProfit),
+				formatFloat(r.ReturnPct),
+				formatFloat(r.MaxDrawdown),
+				formatFloat(r.Sharpe),
+				formatFloat(r.ProfitFactor),
+				strconv.Itoa(len(r.Trades)),
+				"",
+			)
+		}
+		cw.Write(row)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func WriteCSVFile(filename string, spec *Spec, trials []Trial) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating results file: %w", err)
+	}
+	defer file.Close()
+
+	if err := WriteCSV(file, spec, trials); err != nil {
+		return fmt.Errorf("error writing results file: %w", err)
+	}
+	return file.Close()
+}
+
+func PrintTop(w io.Writer, spec *Spec, trials []Trial, n int) {
+	fmt.Fprintf(w, "\n=== Optimization: %d trials, objective %s ===\n", len(trials), spec.Objective)
+	for i, trial := range trials {
+		if i >= n {
+			break
+		}
+		if trial.Err != nil {
+			fmt.Fprintf(w, "%2d. %s: error: %v\n", i+1, trial.Params, trial.Err)
+			continue
+		}
+		r := trial.Result
+		fmt.Fprintf(w, "%2d. %s: score %.4f, net %s, drawdown %.2f%%, sharpe %.2f, profit factor %.2f, %d trades\n",
+			i+1, trial.Params, trial.Score, portfolio.FormatAmount(r.NetProfit, r.Currency), r.MaxDrawdown*100, r.Sharpe, r.ProfitFactor, len(r.Trades))
+	}
+	fmt.Fprintln(w, "========================")
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
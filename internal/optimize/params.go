@@ -0,0 +1,219 @@
+package optimize
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+
+	"trading-bot/internal/bot"
+)
+
+type Method string
+
+const (
+	MethodGrid   Method = "grid"
+	MethodRandom Method = "random"
+)
+
+// Param is one dimension of the search. Path addresses a config field by
+// its JSON keys, e.g. "trading.moving_average.short_period". Either Values
+// lists the candidates explicitly or Min, Max and Step describe a range.
+type Param struct {
+	Path   string  `json:"path"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Step   float64 `json:"step"`
+	Values []any   `json:"values"`
+}
+
+type Spec struct {
+	Strategy  string    `json:"strategy"`
+	Objective Objective `json:"objective"`
+	Method    Method    `json:"method"`
+	Samples   int       `json:"samples"`
+	Seed      int64     `json:"seed"`
+	Params    []Param   `json:"params"`
+}
+
+// ParamSet maps parameter paths to the values of one candidate.
+type ParamSet map[string]any
+
+const maxGridSize = 100000
+
+func LoadSpec(filename string) (*Spec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading optimization spec: %w", err)
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("error parsing optimization spec: %w", err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+func (s *Spec) Validate() error {
+	if s.Objective == "" {
+		s.Objective = ObjectiveSharpe
+	}
+	if _, ok := objectives[s.Objective]; !ok {
+		return fmt.Errorf("unknown objective %q", s.Objective)
+	}
+
+	if s.Method == "" {
+		s.Method = MethodGrid
+	}
+	if s.Method != MethodGrid && s.Method != MethodRandom {
+		return fmt.Errorf("method must be grid or random")
+	}
+	if s.Method == MethodRandom && s.Samples <= 0 {
+		return fmt.Errorf("random search requires a positive sample count")
+	}
+
+	if len(s.Params) == 0 {
+		return fmt.Errorf("optimization spec has no parameters")
+	}
+	for _, p := range s.Params {
+		if p.Path == "" {
+			return fmt.Errorf("parameter path must not be empty")
+		}
+		if len(p.Values) == 0 && (p.Step <= 0 || p.Max < p.Min) {
+			return fmt.Errorf("parameter %s needs values or a min <= max range with a positive step", p.Path)
+		}
+	}
+
+	if s.Method == MethodGrid {
+		size := 1
+		for _, p := range s.Params {
+			size *= len(p.candidates())
+			if size > maxGridSize {
+				return fmt.Errorf("grid has more than %d combinations; use random search", maxGridSize)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p Param) candidates() []any {
+	if len(p.Values) > 0 {
+		return p.Values
+	}
+
+	values := make([]any, 0)
+	steps := int(math.Floor((p.Max-p.Min)/p.Step + 1e-9))
+	for i := 0; i <= steps; i++ {
+		// Round away accumulated float error so 0.1 steps stay readable.
+		v := math.Round((p.Min+float64(i)*p.Step)*1e9) / 1e9
+		values = append(values, v)
+	}
+	return values
+}
+
+// Candidates returns every combination for grid search, or Samples random
+// draws for random search.
+func (s *Spec) Candidates() []ParamSet {
+	if s.Method == MethodRandom {
+		rng := rand.New(rand.NewSource(s.Seed))
+		sets := make([]ParamSet, s.Samples)
+		for i := range sets {
+			sets[i] = make(ParamSet)
+			for _, p := range s.Params {
+				values := p.candidates()
+				sets[i][p.Path] = values[rng.Intn(len(values))]
+			}
+		}
+		return sets
+	}
+
+	sets := []ParamSet{{}}
+	for _, p := range s.Params {
+		next := make([]ParamSet, 0, len(sets)*len(p.candidates()))
+		for _, set := range sets {
+			for _, v := range p.candidates() {
+				combined := make(ParamSet, len(set)+1)
+				for k, existing := range set {
+					combined[k] = existing
+				}
+				combined[p.Path] = v
+				next = append(next, combined)
+			}
+		}
+		sets = next
+	}
+	return sets
+}
+
+// Keys returns the parameter paths in a stable order.
+func (ps ParamSet) Keys() []string {
+	keys := make([]string, 0, len(ps))
+	for k := range ps {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (ps ParamSet) String() string {
+	parts := make([]string, 0, len(ps))
+	for _, k := range ps.Keys() {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, ps[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Apply returns a copy of base with the parameter values set. The copy is
+// made through the config's JSON form so any field can be addressed by path.
+func (ps ParamSet) Apply(base *bot.Config) (*bot.Config, error) {
+	data, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	for path, value := range ps {
+		if err := setPath(tree, strings.Split(path, "."), value); err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", path, err)
+		}
+	}
+
+	if data, err = json.Marshal(tree); err != nil {
+		return nil, err
+	}
+
+	var config bot.Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parameters %s: %w", ps, err)
+	}
+	return &config, nil
+}
+
+func setPath(tree map[string]any, keys []string, value any) error {
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := tree[key].(map[string]any)
+		if !ok {
+			return fmt.Errorf("no config section %q", key)
+		}
+		tree = child
+	}
+
+	last := keys[len(keys)-1]
+	if _, ok := tree[last]; !ok {
+		return fmt.Errorf("no config field %q", last)
+	}
+	tree[last] = value
+	return nil
+}
@@ -0,0 +1,278 @@
+package optimize
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"trading-bot/internal/backtest"
+	"trading-bot/internal/bot"
+	"trading-bot/internal/market"
+	"trading-bot/internal/metrics"
+	"trading-bot/internal/portfolio"
+)
+
+// WalkForwardConfig sizes the rolling windows in bars. Each window optimizes
+// on InSample bars and evaluates the winner on the OutOfSample bars that
+// follow; windows advance by OutOfSample bars so the out-of-sample slices
+// tile the data. Anchored windows keep their start at the first bar.
+type WalkForwardConfig struct {
+	InSample    int
+	OutOfSample int
+	Anchored    bool
+	Workers     int
+}
+
+type Window struct {
+	Index          int
+	InSampleStart  time.Time
+	InSampleEnd    time.Time
+	OutSampleStart time.Time
+	OutSampleEnd   time.Time
+	Params         ParamSet
+	InSampleScore  float64
+	OutOfSample    *backtest.Result
+	Err            error
+}
+
+// ParamStability summarizes how a parameter's chosen value moved between
+// windows. ModeShare is the fraction of windows that picked the most common
+// value; for numeric parameters CV is the coefficient of variation.
+type ParamStability struct {
+	Path      string
+	Values    []any
+	Distinct  int
+	Mode      any
+	ModeShare float64
+	Mean      float64
+	StdDev    float64
+	CV        float64
+	Numeric   bool
+}
+
+type WalkForwardResult struct {
+	Windows        []Window
+	Equity         []metrics.EquityPoint
+	Currency       string
+	InitialBalance float64
+	FinalEquity    float64
+	NetProfit      float64
+	ReturnPct      float64
+	MaxDrawdown    float64
+	Sharpe         float64
+	Trades         int
+	Stability      []ParamStability
+}
+
+func WalkForward(spec *Spec, base *bot.Config, candles []market.Candle, config WalkForwardConfig) (*WalkForwardResult, error) {
+	if config.InSample <= 0 || config.OutOfSample <= 0 {
+		return nil, fmt.Errorf("in-sample and out-of-sample windows must be positive")
+	}
+	if len(candles) < config.InSample+config.OutOfSample {
+		return nil, fmt.Errorf("need at least %d candles for one window, have %d", config.InSample+config.OutOfSample, len(candles))
+	}
+
+	_, quote := base.Assets()
+	result := &WalkForwardResult{Currency: quote, InitialBalance: base.Trading.InitialBalance}
+	equity := base.Trading.InitialBalance
+
+	for start, index := 0, 0; start+config.InSample < len(candles); start, index = start+config.OutOfSample, index+1 {
+		inStart := start
+		if config.Anchored {
+			inStart = 0
+		}
+		inSample := candles[inStart : start+config.InSample]
+		outSample := candles[start+config.InSample : min(start+config.InSample+config.OutOfSample, len(candles))]
+
+		window := Window{
+			Index:          index,
+			InSampleStart:  inSample[0].OpenTime,
+			InSampleEnd:    inSample[len(inSample)-1].CloseTime,
+			OutSampleStart: outSample[0].OpenTime,
+			OutSampleEnd:   outSample[len(outSample)-1].CloseTime,
+		}
+
+		trials := Run(spec, base, inSample, config.Workers)
+		if trials[0].Err != nil {
+			window.Err = fmt.Errorf("no valid parameters in sample: %w", trials[0].Err)
+			result.Windows = append(result.Windows, window)
+			continue
+		}
+		window.Params = trials[0].Params
+		window.InSampleScore = trials[0].Score
+
+		// The strategy is warmed up on the in-sample bars so it can trade
+		// from the first out-of-sample bar.
+		oos, err := runBacktest(spec, base, window.Params, outSample, inSample)
+		if err != nil {
+			window.Err = err
+			result.Windows = append(result.Windows, window)
+			continue
+		}
+		window.OutOfSample = oos
+		result.Windows = append(result.Windows, window)
+
+		// Each window starts from the initial balance; chain them by scaling
+		// each curve to the equity the previous window finished with.
+		scale := equity / oos.InitialBalance
+		for _, point := range oos.Equity {
+			result.Equity = append(result.Equity, metrics.EquityPoint{Time: point.Time, Value: point.Value * scale})
+		}
+		equity = oos.FinalEquity * scale
+		result.Trades += len(oos.Trades)
+	}
+
+	result.FinalEquity = equity
+	result.NetProfit = equity - result.InitialBalance
+	result.ReturnPct = result.NetProfit / result.InitialBalance * 100
+	result.MaxDrawdown = metrics.MaxDrawdown(result.Equity)
+	result.Sharpe = metrics.SharpeRatio(result.Equity)
+	result.Stability = stability(spec, result.Windows)
+
+	return result, nil
+}
+
+func stability(spec *Spec, windows []Window) []ParamStability {
+	stats := make([]ParamStability, 0, len(spec.Params))
+
+	for _, p := range spec.Params {
+		s := ParamStability{Path: p.Path, Numeric: true}
+		counts := make(map[string]int)
+		numbers := make([]float64, 0)
+
+		for _, w := range windows {
+			if w.Params == nil {
+				continue
+			}
+			v := w.Params[p.Path]
+			s.Values = append(s.Values, v)
+
+			key := fmt.Sprint(v)
+			counts[key]++
+			if counts[key] > counts[fmt.Sprint(s.Mode)] || s.Mode == nil {
+				s.Mode = v
+			}
+
+			if f, ok := v.(float64); ok {
+				numbers = append(numbers, f)
+			} else {
+				s.Numeric = false
+			}
+		}
+
+		if len(s.Values) == 0 {
+			stats = append(stats, s)
+			continue
+		}
+
+		s.Distinct = len(counts)
+		s.ModeShare = float64(counts[fmt.Sprint(s.Mode)]) / float64(len(s.Values))
+		if s.Numeric {
+			s.Mean, s.StdDev = metrics.MeanStdDev(numbers)
+			if s.Mean != 0 {
+				s.CV = s.StdDev / math.Abs(s.Mean)
+			}
+		}
+		stats = append(stats, s)
+	}
+
+	return stats
+}
+
+func (r *WalkForwardResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "\n=== Walk-Forward: %d windows ===\n", len(r.Windows))
+	for _, win := range r.Windows {
+		fmt.Fprintf(w, "%2d. OOS %s - %s: ", win.Index+1, win.OutSampleStart.Format(time.DateTime), win.OutSampleEnd.Format(time.DateTime))
+		if win.Err != nil {
+			fmt.Fprintf(w, "error: %v\n", win.Err)
+			continue
+		}
+		fmt.Fprintf(w, "%s | IS score %.4f | OOS net %s, drawdown %.2f%%, %d trades\n",
+			win.Params, win.InSampleScore, portfolio.FormatAmount(win.OutOfSample.NetProfit, r.Currency), win.OutOfSample.MaxDrawdown*100, len(win.OutOfSample.Trades))
+	}
+
+	fmt.Fprintln(w, "\nStitched out-of-sample:")
+	fmt.Fprintf(w, "  Final Equity: %s\n", portfolio.FormatAmount(r.FinalEquity, r.Currency))
+	fmt.Fprintf(w, "  Net Profit: %s (%.2f%%)\n", portfolio.FormatAmount(r.NetProfit, r.Currency), r.ReturnPct)
+	fmt.Fprintf(w, "  Max Drawdown: %.2f%%\n", r.MaxDrawdown*100)
+	fmt.Fprintf(w, "  Sharpe Ratio: %.2f\n", r.Sharpe)
+	fmt.Fprintf(w, "  Trades: %d\n", r.Trades)
+
+	fmt.Fprintln(w, "\nParameter stability:")
+	for _, s := range r.Stability {
+		fmt.Fprintf(w, "  %s: %d distinct values, most common %v in %.0f%% of windows", s.Path, s.Distinct, s.Mode, s.ModeShare*100)
+		if s.Numeric && len(s.Values) > 0 {
+			fmt.Fprintf(w, ", mean %.4g, std dev %.4g, CV %.2f", s.Mean, s.StdDev, s.CV)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "========================")
+}
+
+func (r *WalkForwardResult) WriteWindowsCSV(filename string, spec *Spec) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating walk-forward file: %w", err)
+	}
+	defer file.Close()
+
+	paths := make([]string, len(spec.Params))
+	for i, p := range spec.Params {
+		paths[i] = p.Path
+	}
+	sort.Strings(paths)
+
+	cw := csv.NewWriter(file)
+	header := []string{"window", "in_sample_start", "in_sample_end", "out_sample_start", "out_sample_end"}
+	header = append(header, paths...)
+	header = append(header, "in_sample_score", "oos_net_profit", "oos_return_pct", "oos_max_drawdown", "oos_sharpe", "oos_trades", "error")
+	cw.Write(header)
+
+	for _, win := range r.Windows {
+		row := []string{
+			strconv.Itoa(win.Index + 1),
+			win.InSampleStart.Format(time.RFC3339),
+			win.InSampleEnd.Format(time.RFC3339),
+			win.OutSampleStart.Format(time.RFC3339),
+			win.OutSampleEnd.Format(time.RFC3339),
+		}
+		for _, path := range paths {
+			if win.Params == nil {
+				row = append(row, "")
+			} else {
+				row = append(row, fmt.Sprint(win.Params[path]))
+			}
+		}
+
+		if win.OutOfSample == nil {
+			errText := ""
+			if win.Err != nil {
+				errText = win.Err.Error()
+			}
+			row = append(row, "", "", "", "", "", "", errText)
+		} else {
+			oos := win.OutOfSample
+			row = append(row,
+				formatFloat(win.InSampleScore),
+				formatFloat(oos.NetProfit),
+				formatFloat(oos.ReturnPct),
+				formatFloat(oos.MaxDrawdown),
+				formatFloat(oos.Sharpe),
+				strconv.Itoa(len(oos.Trades)),
+				"",
+			)
+		}
+		cw.Write(row)
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing walk-forward file: %w", err)
+	}
+	return file.Close()
+}
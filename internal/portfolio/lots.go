@@ -0,0 +1,159 @@
+package portfolio
+
+import (
+	"fmt"
+	"time"
+
+	"trading-bot/internal/decimal"
+)
+
+// CostMethod selects which lots a sell consumes, and so its cost basis.
+type CostMethod string
+
+const (
+	CostFIFO    CostMethod = "fifo"
+	CostLIFO    CostMethod = "lifo"
+	CostAverage CostMethod = "average"
+)
+
+func ParseCostMethod(method string) (CostMethod, error) {
+	switch CostMethod(method) {
+	case "":
+		return CostFIFO, nil
+	case CostFIFO, CostLIFO, CostAverage:
+		return CostMethod(method), nil
+	}
+	return "", fmt.Errorf("unknown cost method: %q", method)
+}
+
+// Lot is a quantity bought at one price, quoted in Quote. Under average-cost
+// accounting a position has a single lot priced at the average cost.
+type Lot struct {
+	Quantity decimal.Decimal
+	Price    decimal.Decimal
+	Quote    string
+	Time     time.Time
+}
+
+// Position is an asset's balance with its open lots. CostBasis is in Quote,
+// the quote asset of the lots; an asset bought against several quotes mixes
+// their units. Holdings the books started with have no lots, so only the
+// covered quantity has a known cost. MarketValue and UnrealizedPnL are
+// valuations and work in float64 like the prices they are given.
+type Position struct {
+	Symbol    string
+	Quantity  decimal.Decimal
+	Quote     string
+	CostBasis decimal.Decimal
+	Lots      []Lot
+}
+
+// Covered is the quantity held in lots, which never exceeds Quantity.
+func (pos Position) Covered() decimal.Decimal {
+	covered := decimal.Zero
+	for _, lot := range pos.Lots {
+		covered, _ = covered.Add(lot.Quantity)
+	}
+	return covered
+}
+
+// AveragePrice is the cost basis per unit covered by lots. It lies between
+// the lowest and highest lot price, so it always fits in a Decimal.
+func (pos Position) AveragePrice() decimal.Decimal {
+	covered := pos.Covered()
+	if covered.Sign() <= 0 {
+		return decimal.Zero
+	}
+	average, _ := pos.CostBasis.Div(covered)
+	return average
+}
+
+func (pos Position) MarketValue(price float64) float64 {
+	return pos.Quantity.Float64() * price
+}
+
+// UnrealizedPnL is the gain on the quantity covered by lots; holdings
+// without a known cost are left out.
+func (pos Position) UnrealizedPnL(price float64) float64 {
+	return pos.Covered().Float64()*price - pos.CostBasis.Float64()
+}
+
+// costBasis sums the cost of lots. withLot checks that it fits for every
+// position the portfolio books.
+func costBasis(lots []Lot) (decimal.Decimal, error) {
+	total := decimal.Zero
+	for _, lot := range lots {
+		cost, err := lot.Quantity.Mul(lot.Price)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if total, err = total.Add(cost); err != nil {
+			return decimal.Zero, err
+		}
+	}
+	return total, nil
+}
+
+// withLot returns the symbol's lots with lot added, leaving the portfolio's
+// unchanged until the whole trade is known to fit.
+func (p *Portfolio) withLot(symbol string, lot Lot) ([]Lot, error) {
+	lots := append([]Lot(nil), p.lots[symbol]...)
+	if p.costMethod == CostAverage && len(lots) > 0 {
+		held := lots[0]
+		quantity, err := held.Quantity.Add(lot.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		cost, err := costBasis([]Lot{held, lot})
+		if err != nil {
+			return nil, err
+		}
+		price, err := cost.Div(quantity)
+		if err != nil {
+			return nil, err
+		}
+		lots[0] = Lot{Quantity: quantity, Price: price, Quote: held.Quote, Time: held.Time}
+	} else {
+		lots = append(lots, lot)
+	}
+
+	if _, err := costBasis(lots); err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
+
+// withoutLots returns the symbol's lots with quantity removed in the order
+// the cost method dictates, the quantity the lots covered and its cost. Any
+// quantity beyond the lots has no known cost. The portfolio's lots are left
+// unchanged.
+func (p *Portfolio) withoutLots(symbol string, quantity decimal.Decimal) ([]Lot, decimal.Decimal, decimal.Decimal, error) {
+	lots := append([]Lot(nil), p.lots[symbol]...)
+	covered, cost := decimal.Zero, decimal.Zero
+
+	for quantity.Sign() > 0 && len(lots) > 0 {
+		i := 0
+		if p.costMethod == CostLIFO {
+			i = len(lots) - 1
+		}
+
+		taken := decimal.Min(quantity, lots[i].Quantity)
+		removed, err := costBasis([]Lot{{Quantity: taken, Price: lots[i].Price}})
+		if err != nil {
+			return nil, decimal.Zero, decimal.Zero, err
+		}
+		if cost, err = cost.Add(removed); err != nil {
+			return nil, decimal.Zero, decimal.Zero, err
+		}
+		// taken is at most either quantity, and the lots' total fits, so none
+		// of these overflow.
+		covered, _ = covered.Add(taken)
+		quantity, _ = quantity.Sub(taken)
+		lots[i].Quantity, _ = lots[i].Quantity.Sub(taken)
+
+		if lots[i].Quantity.Sign() <= 0 {
+			lots = append(lots[:i], lots[i+1:]...)
+		}
+	}
+	return lots, covered, cost, nil
+}
@@ -0,0 +1,176 @@
+package portfolio
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"trading-bot/internal/decimal"
+)
+
+func newTestPortfolio(method CostMethod, cash string) *Portfolio {
+	p := NewPortfolioWithBalances(map[string]decimal.Decimal{DefaultCash: decimal.MustParse(cash)}, DefaultCash)
+	p.SetCostMethod(method)
+	p.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return p
+}
+
+func buy(t *testing.T, p *Portfolio, quantity, price string) {
+	t.Helper()
+	if err := p.BuyPair("BTC", DefaultCash, decimal.MustParse(quantity), decimal.MustParse(price), TradeDetails{}); err != nil {
+		t.Fatalf("buy %s at %s failed: %v", quantity, price, err)
+	}
+}
+
+func TestCostMethods(t *testing.T) {
+	tests := []struct {
+		method  CostMethod
+		cost    string
+		pnl     string
+		lots    []string
+		basis   string
+		average string
+	}{
+		// Bought 1 at 100 and 1 at 200, then sold 1.5 at 300.
+		{CostFIFO, "200", "250", []string{"0.5@200"}, "100", "200"},
+		{CostLIFO, "250", "200", []string{"0.5@100"}, "50", "100"},
+		{CostAverage, "225", "225", []string{"0.5@150"}, "75", "150"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			p := newTestPortfolio(tt.method, "1000")
+			buy(t, p, "1", "100")
+			buy(t, p, "1", "200")
+			if err := p.SellPair("BTC", DefaultCash, decimal.MustParse("1.5"), decimal.MustParse("300"), TradeDetails{}); err != nil {
+				t.Fatalf("sell failed: %v", err)
+			}
+
+			sell := p.GetRecentTransactions(1)[0]
+			if sell.CostBasis.String() != tt.cost || sell.RealizedPnL.String() != tt.pnl {
+				t.Errorf("sell cost %s, P&L %s, want %s, %s", sell.CostBasis, sell.RealizedPnL, tt.cost, tt.pnl)
+			}
+
+			pos := p.GetPositionDetails("BTC")
+			lots := make([]string, len(pos.Lots))
+			for i, lot := range pos.Lots {
+				lots[i] = lot.Quantity.String() + "@" + lot.Price.String()
+			}
+			if len(lots) != len(tt.lots) || lots[0] != tt.lots[0] {
+				t.Errorf("lots = %v, want %v", lots, tt.lots)
+			}
+			if pos.CostBasis.String() != tt.basis || pos.AveragePrice().String() != tt.average {
+				t.Errorf("cost basis %s, average %s, want %s, %s", pos.CostBasis, pos.AveragePrice(), tt.basis, tt.average)
+			}
+			if got := p.GetBalance().String(); got != "1150" {
+				t.Errorf("cash = %s, want 1150", got)
+			}
+		})
+	}
+}
+
+func TestSellAcrossLots(t *testing.T) {
+	p := newTestPortfolio(CostFIFO, "1000")
+	buy(t, p, "0.1", "100")
+	buy(t, p, "0.2", "110")
+	buy(t, p, "0.3", "120")
+
+	if err := p.SellPair("BTC", DefaultCash, decimal.MustParse("0.6"), decimal.MustParse("100"), TradeDetails{}); err != nil {
+		t.Fatalf("sell failed: %v", err)
+	}
+	sell := p.GetRecentTransactions(1)[0]
+	if sell.CostBasis.String() != "68" || sell.RealizedPnL.String() != "-8" {
+		t.Errorf("sell cost %s, P&L %s, want 68, -8", sell.CostBasis, sell.RealizedPnL)
+	}
+	if pos := p.GetPositionDetails("BTC"); len(pos.Lots) != 0 || !pos.Quantity.IsZero() {
+		t.Errorf("position after selling everything = %s with %d lots", pos.Quantity, len(pos.Lots))
+	}
+}
+
+func TestSellMoreThanHeld(t *testing.T) {
+	p := newTestPortfolio(CostFIFO, "1000")
+	buy(t, p, "1", "100")
+
+	if err := p.SellPair("BTC", DefaultCash, decimal.MustParse("1.5"), decimal.MustParse("100"), TradeDetails{}); err == nil {
+		t.Fatal("selling more than the position succeeded")
+	}
+	if got := p.GetPosition("BTC").String(); got != "1" {
+		t.Errorf("position = %s, want 1", got)
+	}
+}
+
+func TestOverflowBooksNothing(t *testing.T) {
+	p := newTestPortfolio(CostAverage, "90000000000")
+	buy(t, p, "1", "100")
+
+	// The cost of the buy does not fit in a Decimal.
+	err := p.BuyPair("BTC", DefaultCash, decimal.MustParse("1000000"), decimal.MustParse("1000000"), TradeDetails{})
+	if !errors.Is(err, decimal.ErrOverflow) {
+		t.Fatalf("overflowing buy error = %v, want ErrOverflow", err)
+	}
+	if p.TransactionCount() != 1 || p.GetPosition("BTC").String() != "1" {
+		t.Errorf("overflowing buy changed the books: %d transactions, position %s", p.TransactionCount(), p.GetPosition("BTC"))
+	}
+	if pos := p.GetPositionDetails("BTC"); pos.CostBasis.String() != "100" {
+		t.Errorf("cost basis = %s, want 100", pos.CostBasis)
+	}
+}
+
+// newSeededPortfolio holds 1 BTC the books started with, which has no lots.
+func newSeededPortfolio() *Portfolio {
+	p := NewPortfolioWithBalances(map[string]decimal.Decimal{
+		DefaultCash: decimal.MustParse("1000"),
+		"BTC":       decimal.MustParse("1"),
+	}, DefaultCash)
+	p.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return p
+}
+
+func TestUnrealizedPnLWithoutLots(t *testing.T) {
+	p := newSeededPortfolio()
+	prices := map[string]float64{"BTC": 50000}
+
+	if pnl, ok := p.GetUnrealizedPnL(prices)["BTC"]; ok {
+		t.Errorf("unrealized P&L of a holding without lots = %.2f, want none", pnl)
+	}
+	if got := p.Snapshot(prices).UnrealizedPnL(); got != 0 {
+		t.Errorf("snapshot unrealized P&L = %.2f, want 0", got)
+	}
+}
+
+func TestUnrealizedPnLPartlyCovered(t *testing.T) {
+	p := newSeededPortfolio()
+	buy(t, p, "1", "100")
+	prices := map[string]float64{"BTC": 150}
+
+	if got := p.GetUnrealizedPnL(prices)["BTC"]; got != 50 {
+		t.Errorf("unrealized P&L = %.2f, want 50 on the bought coin only", got)
+	}
+	if got := p.Snapshot(prices).UnrealizedPnL(); got != 50 {
+		t.Errorf("snapshot unrealized P&L = %.2f, want 50", got)
+	}
+	pos := p.GetPositionDetails("BTC")
+	if pos.Covered().String() != "1" || pos.AveragePrice().String() != "100" {
+		t.Errorf("covered %s at %s, want 1 at 100", pos.Covered(), pos.AveragePrice())
+	}
+}
+
+func TestSellBeyondLotsRealizesCoveredQuantity(t *testing.T) {
+	p := newSeededPortfolio()
+	buy(t, p, "1", "100")
+
+	if err := p.SellPair("BTC", DefaultCash, decimal.MustParse("1.5"), decimal.MustParse("150"), TradeDetails{}); err != nil {
+		t.Fatalf("sell failed: %v", err)
+	}
+	sell := p.GetRecentTransactions(1)[0]
+	if sell.CostBasis.String() != "100" || sell.RealizedPnL.String() != "50" {
+		t.Errorf("sell cost %s, P&L %s, want 100, 50", sell.CostBasis, sell.RealizedPnL)
+	}
+	if got := p.GetBalance().String(); got != "1125" {
+		t.Errorf("cash = %s, want 1125", got)
+	}
+	if pos := p.GetPositionDetails("BTC"); pos.Quantity.String() != "0.5" || len(pos.Lots) != 0 {
+		t.Errorf("position = %s with %d lots, want 0.5 without lots", pos.Quantity, len(pos.Lots))
+	}
+}
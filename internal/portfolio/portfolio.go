@@ -2,140 +2,664 @@ package portfolio
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"maps"
+	"sync"
 	"time"
+
+	"trading-bot/internal/decimal"
 )
 
+// Portfolio holds a balance per asset and is safe for concurrent use.
+// Getters return copies, so callers never share state with it.
+//
+// One asset is the cash asset that Buy and Sell pay with and GetBalance
+// reports; every other held asset is a position. Values are reported in the
+// reporting currency, which defaults to the cash asset.
+//
+// Balances, quantities and prices booked into the portfolio are exact
+// decimals; valuations at market prices are float64.
 type Portfolio struct {
-	balance   float64
-	positions map[string]float64
-	history   []Transaction
+	mu         sync.RWMutex
+	balances   map[string]decimal.Decimal
+	cash       string
+	reporting  string
+	lots       map[string][]Lot
+	costMethod CostMethod
+	history    []Transaction
+	now        func() time.Time
+	logger     *slog.Logger
 }
 
+// DefaultCash is the cash asset of portfolios created with NewPortfolio.
+const DefaultCash = "USDT"
+
+// Transaction records a trade of Amount units of Symbol at Price, paid for
+// in Quote; Total is in Quote units. Quote is empty on transactions recorded
+// before portfolios held several currencies and then means the cash asset.
 type Transaction struct {
 	Timestamp time.Time
 	Type      string
 	Symbol    string
-	Amount    float64
-	Price     float64
-	Total     float64
+	Quote     string
+	Amount    decimal.Decimal
+	Price     decimal.Decimal
+	Total     decimal.Decimal
+
+	// CostBasis and RealizedPnL are set on sells: the cost of the lots the
+	// sale consumed and the proceeds above that cost.
+	CostBasis   decimal.Decimal
+	RealizedPnL decimal.Decimal
+
+	TradeDetails
 }
 
-func NewPortfolio(initialBalance float64) *Portfolio {
-	return &Portfolio{
-		balance:   initialBalance,
-		positions: make(map[string]float64),
-		history:   make([]Transaction, 0),
-	}
+// TradeDetails records why a trade was made. It is optional; Buy and Sell
+// leave it empty.
+type TradeDetails struct {
+	Strategy   string
+	Reason     string
+	Confidence float64
+	Indicators map[string]float64
+	OrderType  string
+	LimitPrice float64
+	StopPrice  float64
 }
 
-func (p *Portfolio) GetBalance() float64 {
-	return p.balance
+// logAttrs are the log fields of the signal behind a trade, if any.
+func (d TradeDetails) logAttrs() []any {
+	if d.Reason == "" {
+		return nil
+	}
+	return []any{"strategy", d.Strategy, "confidence", d.Confidence, "reason", d.Reason}
 }
 
-func (p *Portfolio) GetPosition(symbol string) float64 {
-	return p.positions[symbol]
+func NewPortfolio(initialBalance decimal.Decimal) *Portfolio {
+	return NewPortfolioWithBalances(map[string]decimal.Decimal{DefaultCash: initialBalance}, DefaultCash)
 }
 
-func (p *Portfolio) GetPositions() map[string]float64 {
-	positions := make(map[string]float64)
-	for symbol, amount := range p.positions {
-		positions[symbol] = amount
+// NewPortfolioWithBalances starts a portfolio from per-asset balances. Assets
+// other than cash start without lots, so they have no cost basis.
+func NewPortfolioWithBalances(balances map[string]decimal.Decimal, cash string) *Portfolio {
+	p := &Portfolio{
+		balances:   make(map[string]decimal.Decimal),
+		cash:       cash,
+		reporting:  cash,
+		lots:       make(map[string][]Lot),
+		costMethod: CostFIFO,
+		history:    make([]Transaction, 0),
+		now:        time.Now,
+		logger:     slog.Default().With("component", "portfolio"),
+	}
+	for asset, amount := range balances {
+		if !amount.IsZero() || asset == cash {
+			p.balances[asset] = amount
+		}
 	}
+	if _, ok := p.balances[cash]; !ok {
+		p.balances[cash] = decimal.Zero
+	}
+	return p
+}
+
+func (p *Portfolio) CashAsset() string {
+	return p.cash
+}
+
+// SetReportingCurrency chooses the asset values are reported in. Prices
+// passed to valuation methods must then be in that asset.
+func (p *Portfolio) SetReportingCurrency(asset string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reporting = asset
+}
+
+func (p *Portfolio) ReportingCurrency() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.reporting
+}
+
+// SetCostMethod chooses how sells are matched against lots. It should be set
+// before the first trade.
+func (p *Portfolio) SetCostMethod(method CostMethod) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.costMethod = method
+}
+
+// SetClock replaces the source of transaction timestamps, e.g. with the
+// replayed time during a backtest.
+func (p *Portfolio) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+func (p *Portfolio) SetLogger(logger *slog.Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger = logger
+}
+
+// GetBalance returns the balance of the cash asset.
+func (p *Portfolio) GetBalance() decimal.Decimal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.balances[p.cash]
+}
+
+// GetPosition returns the balance of any asset, including the cash asset.
+func (p *Portfolio) GetPosition(symbol string) decimal.Decimal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.balances[symbol]
+}
+
+// GetPositions returns the balances of every asset except the cash asset.
+func (p *Portfolio) GetPositions() map[string]decimal.Decimal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	positions := maps.Clone(p.balances)
+	delete(positions, p.cash)
 	return positions
 }
 
+func (p *Portfolio) GetBalances() map[string]decimal.Decimal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return maps.Clone(p.balances)
+}
+
+// GetPositionDetails returns the symbol's quantity, cost basis and open lots.
+func (p *Portfolio) GetPositionDetails(symbol string) Position {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.positionDetails(symbol)
+}
+
+func (p *Portfolio) positionDetails(symbol string) Position {
+	pos := Position{Symbol: symbol, Quantity: p.balances[symbol]}
+	for _, lot := range p.lots[symbol] {
+		pos.Quote = lot.Quote
+		pos.Lots = append(pos.Lots, lot)
+	}
+	// withLot checked the cost basis fits when the lots were booked.
+	pos.CostBasis, _ = costBasis(pos.Lots)
+	return pos
+}
+
+// GetUnrealizedPnL returns the unrealized P&L, in the reporting currency, of
+// every position that can be valued at currentPrices.
+func (p *Portfolio) GetUnrealizedPnL(currentPrices map[string]float64) map[string]float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	pnl := make(map[string]float64)
+	for symbol := range p.balances {
+		if symbol == p.cash {
+			continue
+		}
+		if value, ok := unrealizedPnL(p.positionDetails(symbol), currentPrices, p.reporting); ok {
+			pnl[symbol] = value
+		}
+	}
+	return pnl
+}
+
+// unrealizedPnL values the quantity a position's lots cover and its cost
+// basis in the reporting currency, converting the cost at the quote asset's
+// current price. Positions without lots have no known cost and no value.
+func unrealizedPnL(pos Position, prices map[string]float64, reporting string) (float64, bool) {
+	price, ok := prices[pos.Symbol]
+	if !ok || len(pos.Lots) == 0 {
+		return 0, false
+	}
+
+	rate := 1.0
+	if pos.Quote != "" && pos.Quote != reporting {
+		if rate, ok = prices[pos.Quote]; !ok {
+			return 0, false
+		}
+	}
+	return pos.Covered().Float64()*price - pos.CostBasis.Float64()*rate, true
+}
+
 func (p *Portfolio) GetHistory() []Transaction {
-	return p.history
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return copyTransactions(p.history)
+}
+
+// RealizedPnL sums the realized P&L of every sell. It is bounded by the cash
+// that changed hands, which fit in the books, so the sum cannot overflow.
+func (p *Portfolio) RealizedPnL() decimal.Decimal {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	total := decimal.Zero
+	for _, t := range p.history {
+		total, _ = total.Add(t.RealizedPnL)
+	}
+	return total
 }
 
-func (p *Portfolio) Buy(symbol string, dollarAmount float64, price float64) error {
-	if dollarAmount > p.balance {
-		return fmt.Errorf("insufficient balance: have %.2f, need %.2f", p.balance, dollarAmount)
+func (p *Portfolio) TransactionCount() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.history)
+}
+
+// copyTransactions copies transactions along with their indicator maps.
+func copyTransactions(transactions []Transaction) []Transaction {
+	copied := make([]Transaction, len(transactions))
+	for i, t := range transactions {
+		copied[i] = t
+		if t.Indicators != nil {
+			copied[i].Indicators = maps.Clone(t.Indicators)
+		}
+	}
+	return copied
+}
+
+func (p *Portfolio) Buy(symbol string, dollarAmount, price decimal.Decimal) error {
+	return p.BuyWithDetails(symbol, dollarAmount, price, TradeDetails{})
+}
+
+// BuyWithDetails spends at most dollarAmount of cash on symbol, buying the
+// largest quantity that amount covers at price.
+func (p *Portfolio) BuyWithDetails(symbol string, dollarAmount, price decimal.Decimal, details TradeDetails) error {
+	if price.Sign() <= 0 {
+		return fmt.Errorf("buy price must be positive, got %s", price)
 	}
+	quantity, err := dollarAmount.DivTrunc(price)
+	if err != nil {
+		return fmt.Errorf("error sizing buy: %w", err)
+	}
+	return p.BuyPair(symbol, p.cash, quantity, price, details)
+}
 
-	quantity := dollarAmount / price
-	p.balance -= dollarAmount
-	p.positions[symbol] += quantity
+// BuyPair buys quantity of base at price, quoted in and paid for in quote.
+// Nothing is booked if any resulting amount would overflow.
+func (p *Portfolio) BuyPair(base, quote string, quantity, price decimal.Decimal, details TradeDetails) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if quantity.Sign() <= 0 {
+		return fmt.Errorf("buy quantity must be positive, got %s", quantity)
+	}
+	quoteAmount, err := quantity.Mul(price)
+	if err != nil {
+		return fmt.Errorf("error pricing buy: %w", err)
+	}
+	if quoteAmount.Cmp(p.balances[quote]) > 0 {
+		return fmt.Errorf("insufficient %s balance: have %s, need %s", quote, p.balances[quote], quoteAmount)
+	}
+
+	now := p.now()
+	quoteBalance, err := p.balances[quote].Sub(quoteAmount)
+	if err != nil {
+		return fmt.Errorf("error booking buy: %w", err)
+	}
+	position, err := p.balances[base].Add(quantity)
+	if err != nil {
+		return fmt.Errorf("error booking buy: %w", err)
+	}
+	lots, err := p.withLot(base, Lot{Quantity: quantity, Price: price, Quote: quote, Time: now})
+	if err != nil {
+		return fmt.Errorf("error booking buy: %w", err)
+	}
+
+	p.setBalance(quote, quoteBalance)
+	p.setBalance(base, position)
+	p.lots[base] = lots
 
 	transaction := Transaction{
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Type:      "BUY",
-		Symbol:    symbol,
+		Symbol:    base,
+		Quote:     quote,
 		Amount:    quantity,
 		Price:     price,
-		Total:     dollarAmount,
+		Total:     quoteAmount,
+
+		TradeDetails: details,
 	}
 	p.history = append(p.history, transaction)
 
-	log.Printf("BUY: %.6f %s at $%.2f (Total: $%.2f)", quantity, symbol, price, dollarAmount)
+	p.logger.Info("Bought", append([]any{"symbol", base, "quote", quote, "quantity", quantity, "price", price, "total", quoteAmount}, details.logAttrs()...)...)
 	return nil
 }
 
-func (p *Portfolio) Sell(symbol string, quantity float64, price float64) error {
-	if position, exists := p.positions[symbol]; !exists || position < quantity {
-		return fmt.Errorf("insufficient %s position: have %.6f, trying to sell %.6f", symbol, p.positions[symbol], quantity)
-	}
+func (p *Portfolio) Sell(symbol string, quantity, price decimal.Decimal) error {
+	return p.SellWithDetails(symbol, quantity, price, TradeDetails{})
+}
+
+func (p *Portfolio) SellWithDetails(symbol string, quantity, price decimal.Decimal, details TradeDetails) error {
+	return p.SellPair(symbol, p.cash, quantity, price, details)
+}
 
-	dollarAmount := quantity * price
-	p.positions[symbol] -= quantity
-	p.balance += dollarAmount
+// SellPair sells quantity of base for quote at price, quoted in quote.
+// Nothing is booked if any resulting amount would overflow.
+func (p *Portfolio) SellPair(base, quote string, quantity, price decimal.Decimal, details TradeDetails) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	if p.positions[symbol] <= 0 {
-		delete(p.positions, symbol)
+	if quantity.Sign() <= 0 {
+		return fmt.Errorf("sell quantity must be positive, got %s", quantity)
+	}
+	if position, exists := p.balances[base]; !exists || position.Cmp(quantity) < 0 {
+		return fmt.Errorf("insufficient %s position: have %s, trying to sell %s", base, p.balances[base], quantity)
+	}
+
+	proceeds, err := quantity.Mul(price)
+	if err != nil {
+		return fmt.Errorf("error pricing sell: %w", err)
+	}
+	lots, covered, costBasis, err := p.withoutLots(base, quantity)
+	if err != nil {
+		return fmt.Errorf("error booking sell: %w", err)
 	}
+	// Only the quantity the lots covered has a cost to realize a gain over.
+	coveredProceeds, err := covered.Mul(price)
+	if err != nil {
+		return fmt.Errorf("error pricing sell: %w", err)
+	}
+	realized, err := coveredProceeds.Sub(costBasis)
+	if err != nil {
+		return fmt.Errorf("error booking sell: %w", err)
+	}
+	quoteBalance, err := p.balances[quote].Add(proceeds)
+	if err != nil {
+		return fmt.Errorf("error booking sell: %w", err)
+	}
+	// The position covers quantity, so this cannot overflow.
+	position, _ := p.balances[base].Sub(quantity)
+
+	p.lots[base] = lots
+	p.setBalance(base, position)
+	p.setBalance(quote, quoteBalance)
 
 	transaction := Transaction{
-		Timestamp: time.Now(),
+		Timestamp: p.now(),
 		Type:      "SELL",
-		Symbol:    symbol,
+		Symbol:    base,
+		Quote:     quote,
 		Amount:    quantity,
 		Price:     price,
-		Total:     dollarAmount,
+		Total:     proceeds,
+
+		CostBasis:   costBasis,
+		RealizedPnL: realized,
+
+		TradeDetails: details,
 	}
 	p.history = append(p.history, transaction)
 
-	log.Printf("SELL: %.6f %s at $%.2f (Total: $%.2f)", quantity, symbol, price, dollarAmount)
+	p.logger.Info("Sold", append([]any{"symbol", base, "quote", quote, "quantity", quantity, "price", price, "total", proceeds, "pnl", transaction.RealizedPnL}, details.logAttrs()...)...)
+	return nil
+}
+
+// setBalance sets an asset's balance, dropping emptied positions and their
+// lots. The cash asset is always kept.
+func (p *Portfolio) setBalance(asset string, balance decimal.Decimal) {
+	p.balances[asset] = balance
+	if asset != p.cash && balance.Sign() <= 0 {
+		delete(p.balances, asset)
+		delete(p.lots, asset)
+	}
+}
+
+// Precision is the number of decimal places amounts of asset are shown with:
+// cents for dollar-like assets and every digit a Decimal holds for anything
+// else.
+func Precision(asset string) int {
+	switch asset {
+	case "USDT", "BUSD", "USDC", "FDUSD", "TUSD", "USD":
+		return 2
+	}
+	return decimal.Places
+}
+
+// FormatAmount prints dollar-like assets as dollars and anything else with
+// enough precision for crypto quotes.
+func FormatAmount(amount float64, asset string) string {
+	if Precision(asset) == 2 {
+		return fmt.Sprintf("$%.2f", amount)
+	}
+	return fmt.Sprintf("%.*f %s", Precision(asset), amount, asset)
+}
+
+// AdjustBalance sets the cash balance, recording the difference as an ADJUST
+// transaction with an empty symbol, e.g. to adopt the exchange's balance.
+func (p *Portfolio) AdjustBalance(balance decimal.Decimal, reason string) (Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delta, err := balance.Sub(p.balances[p.cash])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("error adjusting balance: %w", err)
+	}
+	return p.adjustBalance(delta, reason)
+}
+
+// AdjustBalanceBy moves the cash balance by delta, like AdjustBalance but
+// without a separate read of the balance another trade could race with.
+func (p *Portfolio) AdjustBalanceBy(delta decimal.Decimal, reason string) (Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.adjustBalance(delta, reason)
+}
+
+func (p *Portfolio) adjustBalance(delta decimal.Decimal, reason string) (Transaction, error) {
+	balance, err := p.balances[p.cash].Add(delta)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("error adjusting balance: %w", err)
+	}
+
+	transaction := Transaction{
+		Timestamp: p.now(),
+		Type:      "ADJUST",
+		Total:     delta,
+
+		TradeDetails: TradeDetails{Reason: reason},
+	}
+	p.balances[p.cash] = balance
+	p.history = append(p.history, transaction)
+
+	p.logger.Info("Adjusted balance", "symbol", p.cash, "change", delta, "balance", balance, "reason", reason)
+	return transaction, nil
+}
+
+// AdjustPosition sets a position's quantity, recording the difference as an
+// ADJUST transaction. Added quantity opens a lot at price; removed quantity
+// is taken from lots like a sell, without realizing P&L.
+func (p *Portfolio) AdjustPosition(symbol string, quantity, price decimal.Decimal, reason string) (Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delta, err := quantity.Sub(p.balances[symbol])
+	if err != nil {
+		return Transaction{}, fmt.Errorf("error adjusting position: %w", err)
+	}
+	return p.adjustPositionBy(symbol, delta, price, reason)
+}
+
+// AdjustPositionBy moves a position by delta, like AdjustPosition but
+// without a separate read of the quantity.
+func (p *Portfolio) AdjustPositionBy(symbol string, delta, price decimal.Decimal, reason string) (Transaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.adjustPositionBy(symbol, delta, price, reason)
+}
+
+func (p *Portfolio) adjustPositionBy(symbol string, delta, price decimal.Decimal, reason string) (Transaction, error) {
+	transaction := Transaction{
+		Timestamp: p.now(),
+		Type:      "ADJUST",
+		Symbol:    symbol,
+		Amount:    delta,
+		Price:     price,
+
+		TradeDetails: TradeDetails{Reason: reason},
+	}
+	if err := p.adjustPosition(symbol, delta, price, transaction.Timestamp); err != nil {
+		return Transaction{}, fmt.Errorf("error adjusting position: %w", err)
+	}
+	p.history = append(p.history, transaction)
+
+	p.logger.Info("Adjusted position", "symbol", symbol, "change", delta, "quantity", p.balances[symbol], "reason", reason)
+	return transaction, nil
+}
+
+func (p *Portfolio) adjustPosition(symbol string, delta, price decimal.Decimal, at time.Time) error {
+	balance, err := p.balances[symbol].Add(delta)
+	if err != nil {
+		return err
+	}
+	if symbol == p.cash {
+		p.setBalance(symbol, balance)
+		return nil
+	}
+
+	var lots []Lot
+	if delta.Sign() > 0 {
+		lots, err = p.withLot(symbol, Lot{Quantity: delta, Price: price, Quote: p.cash, Time: at})
+	} else {
+		lots, _, _, err = p.withoutLots(symbol, delta.Neg())
+	}
+	if err != nil {
+		return err
+	}
+	p.lots[symbol] = lots
+	p.setBalance(symbol, balance)
+	return nil
+}
+
+// Replay reapplies a recorded transaction, e.g. from a journal, without
+// logging it or checking the balance. It fails, booking nothing, if an
+// amount would overflow.
+func (p *Portfolio) Replay(t Transaction) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	quote := t.Quote
+	if quote == "" {
+		quote = p.cash
+	}
+
+	var err error
+	switch t.Type {
+	case "BUY":
+		err = p.replayTrade(t.Symbol, quote, t.Amount, t.Total.Neg(), func() ([]Lot, error) {
+			return p.withLot(t.Symbol, Lot{Quantity: t.Amount, Price: t.Price, Quote: quote, Time: t.Timestamp})
+		})
+	case "SELL":
+		err = p.replayTrade(t.Symbol, quote, t.Amount.Neg(), t.Total, func() ([]Lot, error) {
+			lots, _, _, err := p.withoutLots(t.Symbol, t.Amount)
+			return lots, err
+		})
+	case "ADJUST":
+		if t.Symbol == "" {
+			err = p.adjustPosition(p.cash, t.Total, decimal.Zero, t.Timestamp)
+		} else {
+			err = p.adjustPosition(t.Symbol, t.Amount, t.Price, t.Timestamp)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error replaying %s transaction: %w", t.Type, err)
+	}
+
+	p.history = append(p.history, t)
+	return nil
+}
+
+// replayTrade moves base and quote by the given amounts and replaces base's
+// lots, after checking that all of it fits.
+func (p *Portfolio) replayTrade(base, quote string, baseDelta, quoteDelta decimal.Decimal, lots func() ([]Lot, error)) error {
+	position, err := p.balances[base].Add(baseDelta)
+	if err != nil {
+		return err
+	}
+	quoteBalance, err := p.balances[quote].Add(quoteDelta)
+	if err != nil {
+		return err
+	}
+	newLots, err := lots()
+	if err != nil {
+		return err
+	}
+
+	p.lots[base] = newLots
+	p.setBalance(base, position)
+	p.setBalance(quote, quoteBalance)
 	return nil
 }
 
 func (p *Portfolio) GetTotalValue(currentPrices map[string]float64) float64 {
-	totalValue := p.balance
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.totalValue(currentPrices)
+}
 
-	for symbol, quantity := range p.positions {
-		if price, exists := currentPrices[symbol]; exists {
-			totalValue += quantity * price
+// totalValue values every balance in the reporting currency. Assets without
+// a price are left out.
+func (p *Portfolio) totalValue(currentPrices map[string]float64) float64 {
+	totalValue := 0.0
+
+	for asset, quantity := range p.balances {
+		if asset == p.reporting {
+			totalValue += quantity.Float64()
+		} else if price, exists := currentPrices[asset]; exists {
+			totalValue += quantity.Float64() * price
 		}
 	}
 
 	return totalValue
 }
 
-func (p *Portfolio) PrintSummary(currentPrices map[string]float64) {
-	fmt.Println("\n=== Portfolio Summary ===")
-	fmt.Printf("Cash Balance: $%.2f\n", p.balance)
+func (p *Portfolio) PrintSummary(w io.Writer, currentPrices map[string]float64) {
+	snapshot := p.Snapshot(currentPrices)
+
+	reporting := snapshot.ReportingCurrency
 
-	if len(p.positions) > 0 {
-		fmt.Println("Holdings:")
-		for symbol, quantity := range p.positions {
-			if price, exists := currentPrices[symbol]; exists {
-				value := quantity * price
-				fmt.Printf("  %s: %.6f (Value: $%.2f at $%.2f)\n", symbol, quantity, value, price)
-			} else {
-				fmt.Printf("  %s: %.6f (Price unknown)\n", symbol, quantity)
+	fmt.Fprintln(w, "\n=== Portfolio Summary ===")
+	fmt.Fprintf(w, "Cash Balance: %s\n", FormatAmount(snapshot.Balance.Float64(), snapshot.Cash))
+
+	if len(snapshot.Positions) > 0 {
+		fmt.Fprintln(w, "Holdings:")
+		for _, symbol := range snapshot.Symbols() {
+			pos := snapshot.Positions[symbol]
+			price, exists := snapshot.Prices[symbol]
+			if !exists {
+				fmt.Fprintf(w, "  %s: %s (price unknown)\n", symbol, pos.Quantity)
+				continue
+			}
+
+			line := fmt.Sprintf("  %s: %s (Value: %s at %s", symbol, pos.Quantity,
+				FormatAmount(pos.MarketValue(price), reporting), FormatAmount(price, reporting))
+			if len(pos.Lots) > 0 {
+				line += fmt.Sprintf(", avg cost %s", FormatAmount(pos.AveragePrice().Float64(), pos.Quote))
+				if pnl, ok := unrealizedPnL(pos, snapshot.Prices, reporting); ok {
+					line += fmt.Sprintf(", unrealized P&L %s", FormatAmount(pnl, reporting))
+				}
 			}
+			fmt.Fprintln(w, line+")")
 		}
 	}
 
-	totalValue := p.GetTotalValue(currentPrices)
-	fmt.Printf("Total Portfolio Value: $%.2f\n", totalValue)
-	fmt.Println("========================")
+	fmt.Fprintf(w, "Total Portfolio Value: %s\n", FormatAmount(snapshot.Equity, reporting))
+	fmt.Fprintln(w, "========================")
 }
 
 func (p *Portfolio) GetRecentTransactions(count int) []Transaction {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
 	start := len(p.history) - count
 	if start < 0 {
 		start = 0
 	}
-	return p.history[start:]
+	return copyTransactions(p.history[start:])
 }
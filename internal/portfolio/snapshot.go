@@ -0,0 +1,86 @@
+package portfolio
+
+import (
+	"maps"
+	"sort"
+	"time"
+
+	"trading-bot/internal/decimal"
+)
+
+// Snapshot is a consistent view of the portfolio taken under one lock. It
+// shares no memory with the portfolio.
+// Balance is the cash asset's balance and Balances holds every asset's.
+// Prices and Equity are in ReportingCurrency.
+type Snapshot struct {
+	Time              time.Time
+	Cash              string
+	ReportingCurrency string
+	Balance           decimal.Decimal
+	Balances          map[string]decimal.Decimal
+	Positions         map[string]Position
+	Prices            map[string]float64
+	Equity            float64
+	Transactions      int
+}
+
+// Snapshot captures the balances, positions and their lots, and the equity
+// at currentPrices. Assets without a price count at zero toward Equity.
+func (p *Portfolio) Snapshot(currentPrices map[string]float64) Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	snapshot := Snapshot{
+		Time:              p.now(),
+		Cash:              p.cash,
+		ReportingCurrency: p.reporting,
+		Balance:           p.balances[p.cash],
+		Balances:          maps.Clone(p.balances),
+		Positions:         make(map[string]Position, len(p.balances)),
+		Prices:            maps.Clone(currentPrices),
+		Equity:            p.totalValue(currentPrices),
+		Transactions:      len(p.history),
+	}
+	for symbol := range p.balances {
+		if symbol != p.cash {
+			snapshot.Positions[symbol] = p.positionDetails(symbol)
+		}
+	}
+	return snapshot
+}
+
+// Unpriced returns the held assets, in sorted order, that Prices has no
+// price for. Equity undercounts them, so it is only complete when there are
+// none.
+func (s Snapshot) Unpriced() []string {
+	unpriced := make([]string, 0)
+	for asset, quantity := range s.Balances {
+		if _, ok := s.Prices[asset]; !ok && asset != s.ReportingCurrency && !quantity.IsZero() {
+			unpriced = append(unpriced, asset)
+		}
+	}
+	sort.Strings(unpriced)
+	return unpriced
+}
+
+// Symbols returns the held symbols in sorted order.
+func (s Snapshot) Symbols() []string {
+	symbols := make([]string, 0, len(s.Positions))
+	for symbol := range s.Positions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// UnrealizedPnL sums, in the reporting currency, the unrealized P&L of the
+// positions that can be valued.
+func (s Snapshot) UnrealizedPnL() float64 {
+	total := 0.0
+	for _, pos := range s.Positions {
+		if value, ok := unrealizedPnL(pos, s.Prices, s.ReportingCurrency); ok {
+			total += value
+		}
+	}
+	return total
+}
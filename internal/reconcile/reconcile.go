@@ -0,0 +1,135 @@
+package reconcile
+
+import (
+	"fmt"
+	"math"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/exchange"
+)
+
+// Policy decides what the bot does about discrepancies.
+type Policy string
+
+const (
+	PolicyReport Policy = "report"
+	PolicyAdopt  Policy = "adopt"
+	PolicyHalt   Policy = "halt"
+)
+
+func ParsePolicy(policy string) (Policy, error) {
+	switch Policy(policy) {
+	case "":
+		return PolicyReport, nil
+	case PolicyReport, PolicyAdopt, PolicyHalt:
+		return Policy(policy), nil
+	}
+	return "", fmt.Errorf("unknown reconcile policy: %q", policy)
+}
+
+type Kind string
+
+const (
+	KindBalance  Kind = "balance"
+	KindPosition Kind = "position"
+	// KindMissingOrder is an order the bot thinks is open but the exchange
+	// does not list; KindUnknownOrder is the reverse.
+	KindMissingOrder Kind = "missing_order"
+	KindUnknownOrder Kind = "unknown_order"
+)
+
+// Discrepancy compares what the exchange should hold according to the local
+// books with what it does hold. For orders both values are zero.
+type Discrepancy struct {
+	Kind     Kind
+	Asset    string
+	OrderID  int64
+	Expected decimal.Decimal
+	Actual   decimal.Decimal
+}
+
+// Difference is how far the local books must move to match the exchange.
+// Both sides are nonnegative balances, so it cannot overflow.
+func (d Discrepancy) Difference() decimal.Decimal {
+	difference, _ := d.Actual.Sub(d.Expected)
+	return difference
+}
+
+func (d Discrepancy) String() string {
+	switch d.Kind {
+	case KindMissingOrder:
+		return fmt.Sprintf("order %d is open locally but not on the exchange", d.OrderID)
+	case KindUnknownOrder:
+		return fmt.Sprintf("order %d is open on the exchange but not tracked locally", d.OrderID)
+	}
+	difference := d.Difference().String()
+	if d.Difference().Sign() >= 0 {
+		difference = "+" + difference
+	}
+	return fmt.Sprintf("%s %s: expected %s, exchange has %s (difference %s)", d.Asset, d.Kind, d.Expected, d.Actual, difference)
+}
+
+// Local is the bot's view of the account for one trading pair.
+type Local struct {
+	BaseAsset  string
+	QuoteAsset string
+	Cash       decimal.Decimal
+	Position   decimal.Decimal
+	OpenOrders []exchange.OrderResponse
+}
+
+// Compare reports where the exchange disagrees with the local books by more
+// than tolerance, a fraction of the larger value. The bot books orders as
+// they execute, so the funds an open order locks still count towards the
+// account totals it is compared with.
+func Compare(local Local, account *exchange.Account, openOrders []exchange.OrderResponse, tolerance float64) ([]Discrepancy, error) {
+	discrepancies := make([]Discrepancy, 0)
+
+	remote := make(map[int64]exchange.OrderResponse, len(openOrders))
+	for _, order := range openOrders {
+		remote[order.OrderID] = order
+	}
+
+	tracked := make(map[int64]bool, len(local.OpenOrders))
+	for _, order := range local.OpenOrders {
+		tracked[order.OrderID] = true
+
+		if _, ok := remote[order.OrderID]; !ok {
+			discrepancies = append(discrepancies, Discrepancy{Kind: KindMissingOrder, OrderID: order.OrderID})
+		}
+	}
+
+	for _, order := range openOrders {
+		if !tracked[order.OrderID] {
+			discrepancies = append(discrepancies, Discrepancy{Kind: KindUnknownOrder, OrderID: order.OrderID})
+		}
+	}
+
+	cash, err := account.Total(local.QuoteAsset)
+	if err != nil {
+		return nil, err
+	}
+	if differs(local.Cash, cash, tolerance) {
+		discrepancies = append(discrepancies, Discrepancy{Kind: KindBalance, Asset: local.QuoteAsset, Expected: local.Cash, Actual: cash})
+	}
+	position, err := account.Total(local.BaseAsset)
+	if err != nil {
+		return nil, err
+	}
+	if differs(local.Position, position, tolerance) {
+		discrepancies = append(discrepancies, Discrepancy{Kind: KindPosition, Asset: local.BaseAsset, Expected: local.Position, Actual: position})
+	}
+
+	return discrepancies, nil
+}
+
+// differs reports whether expected and actual disagree by more than
+// tolerance. Exact decimals need no allowance for float rounding, so any
+// difference counts when tolerance is zero.
+func differs(expected, actual decimal.Decimal, tolerance float64) bool {
+	if expected.Cmp(actual) == 0 {
+		return false
+	}
+	diff := math.Abs(expected.Float64() - actual.Float64())
+	return diff > tolerance*math.Max(expected.Abs().Float64(), actual.Abs().Float64())
+}
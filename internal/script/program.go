@@ -0,0 +1,457 @@
+package script
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"trading-bot/internal/indicator"
+)
+
+type valueType int
+
+const (
+	typeNumber valueType = iota
+	typeBool
+)
+
+func (t valueType) String() string {
+	if t == typeBool {
+		return "boolean"
+	}
+	return "number"
+}
+
+type paramKind int
+
+const (
+	// paramPeriod must be a positive integer literal.
+	paramPeriod paramKind = iota
+	// paramConstant must be a numeric literal.
+	paramConstant
+	// paramSeries may be any numeric expression.
+	paramSeries
+)
+
+type function struct {
+	params  []paramKind
+	returns valueType
+	// lookback reports how many bars the call needs given its literal
+	// arguments and the lookbacks of its series arguments.
+	lookback func(consts []float64, series []int) int
+	eval     func(s *Series, consts []float64, series []func(shift int) (float64, error), shift int) (float64, error)
+}
+
+// Series holds the bars a program is evaluated against, oldest first.
+type Series struct {
+	Open   []float64
+	High   []float64
+	Low    []float64
+	Close  []float64
+	Volume []float64
+}
+
+func (s *Series) Len() int {
+	return len(s.Close)
+}
+
+var errNotEnoughData = errors.New("not enough data")
+
+var identifiers = map[string]func(s *Series) []float64{
+	"open":   func(s *Series) []float64 { return s.Open },
+	"high":   func(s *Series) []float64 { return s.High },
+	"low":    func(s *Series) []float64 { return s.Low },
+	"close":  func(s *Series) []float64 { return s.Close },
+	"price":  func(s *Series) []float64 { return s.Close },
+	"volume": func(s *Series) []float64 { return s.Volume },
+}
+
+func closeIndicator(fn func(values []float64, period int) float64, extra int) function {
+	return function{
+		params:   []paramKind{paramPeriod},
+		returns:  typeNumber,
+		lookback: func(consts []float64, _ []int) int { return int(consts[0]) + extra },
+		eval: func(s *Series, consts []float64, _ []func(int) (float64, error), shift int) (float64, error) {
+			closes := s.Close[:s.Len()-shift]
+			if len(closes) < int(consts[0])+extra {
+				return 0, errNotEnoughData
+			}
+			return fn(closes, int(consts[0])), nil
+		},
+	}
+}
+
+func bandFunction(side float64) function {
+	return function{
+		params:   []paramKind{paramPeriod, paramConstant},
+		returns:  typeNumber,
+		lookback: func(consts []float64, _ []int) int { return int(consts[0]) },
+		eval: func(s *Series, consts []float64, _ []func(int) (float64, error), shift int) (float64, error) {
+			closes := s.Close[:s.Len()-shift]
+			if len(closes) < int(consts[0]) {
+				return 0, errNotEnoughData
+			}
+			upper, middle, _ := indicator.BollingerBands(closes, int(consts[0]), consts[1])
+			return middle + side*(upper-middle), nil
+		},
+	}
+}
+
+func macdFunction(part func(macd, signal float64) float64) function {
+	return function{
+		params:   []paramKind{paramPeriod, paramPeriod, paramPeriod},
+		returns:  typeNumber,
+		lookback: func(consts []float64, _ []int) int { return int(consts[1] + consts[2]) },
+		eval: func(s *Series, consts []float64, _ []func(int) (float64, error), shift int) (float64, error) {
+			closes := s.Close[:s.Len()-shift]
+			if len(closes) < int(consts[1]+consts[2]) {
+				return 0, errNotEnoughData
+			}
+			return part(indicator.MACD(closes, int(consts[0]), int(consts[1]), int(consts[2]))), nil
+		},
+	}
+}
+
+func crossFunction(above bool) function {
+	return function{
+		params:   []paramKind{paramSeries, paramSeries},
+		returns:  typeBool,
+		lookback: func(_ []float64, series []int) int { return max(series[0], series[1]) + 1 },
+		eval: func(_ *Series, _ []float64, series []func(int) (float64, error), shift int) (float64, error) {
+			values := make([]float64, 4)
+			for i, arg := range []struct{ fn, shift int }{{0, shift}, {1, shift}, {0, shift + 1}, {1, shift + 1}} {
+				v, err := series[arg.fn](arg.shift)
+				if err != nil {
+					return 0, err
+				}
+				values[i] = v
+			}
+			a, b, prevA, prevB := values[0], values[1], values[2], values[3]
+			if above {
+				return boolValue(a > b && prevA <= prevB), nil
+			}
+			return boolValue(a < b && prevA >= prevB), nil
+		},
+	}
+}
+
+var functions = map[string]function{
+	"sma":       closeIndicator(indicator.SMA, 0),
+	"ema":       closeIndicator(indicator.EMA, 0),
+	"rsi":       closeIndicator(indicator.RSI, 1),
+	"stddev":    closeIndicator(indicator.StdDev, 0),
+	"highest":   closeIndicator(indicator.Highest, 0),
+	"lowest":    closeIndicator(indicator.Lowest, 0),
+	"bb_upper":  bandFunction(1),
+	"bb_lower":  bandFunction(-1),
+	"bb_middle": bandFunction(0),
+
+	"macd":        macdFunction(func(macd, _ float64) float64 { return macd }),
+	"macd_signal": macdFunction(func(_, signal float64) float64 { return signal }),
+	"macd_hist":   macdFunction(func(macd, signal float64) float64 { return macd - signal }),
+
+	"cross_above": crossFunction(true),
+	"cross_below": crossFunction(false),
+
+	"prev": {
+		params:   []paramKind{paramSeries, paramPeriod},
+		returns:  typeNumber,
+		lookback: func(consts []float64, series []int) int { return series[0] + int(consts[0]) },
+		eval: func(_ *Series, consts []float64, series []func(int) (float64, error), shift int) (float64, error) {
+			return series[0](shift + int(consts[0]))
+		},
+	},
+	"abs": {
+		params:   []paramKind{paramSeries},
+		returns:  typeNumber,
+		lookback: func(_ []float64, series []int) int { return series[0] },
+		eval: func(_ *Series, _ []float64, series []func(int) (float64, error), shift int) (float64, error) {
+			v, err := series[0](shift)
+			return math.Abs(v), err
+		},
+	},
+}
+
+// Program is a compiled, type-checked rule.
+type Program struct {
+	src      string
+	root     node
+	lookback int
+	calls    []*callNode
+}
+
+// Compile parses src and checks that it is a boolean expression using only
+// known identifiers and functions with correctly typed arguments.
+func Compile(src string) (*Program, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	prog := &Program{src: src, root: root}
+
+	t, lookback, err := prog.check(root)
+	if err != nil {
+		return nil, err
+	}
+	if t != typeBool {
+		return nil, &Error{Pos: root.position(), Msg: fmt.Sprintf("rule must be a boolean expression, found %s", t)}
+	}
+
+	prog.lookback = lookback
+	return prog, nil
+}
+
+func (p *Program) String() string {
+	return p.src
+}
+
+// Lookback is the number of bars the program needs before it can evaluate.
+func (p *Program) Lookback() int {
+	return p.lookback
+}
+
+func (p *Program) check(n node) (valueType, int, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return typeNumber, 0, nil
+
+	case *boolNode:
+		return typeBool, 0, nil
+
+	case *identNode:
+		if _, ok := identifiers[n.name]; !ok {
+			return 0, 0, &Error{Pos: n.pos, Msg: fmt.Sprintf("unknown identifier %q", n.name)}
+		}
+		return typeNumber, 1, nil
+
+	case *unaryNode:
+		t, lookback, err := p.check(n.operand)
+		if err != nil {
+			return 0, 0, err
+		}
+		want := typeNumber
+		if n.op == "not" {
+			want = typeBool
+		}
+		if t != want {
+			return 0, 0, &Error{Pos: n.pos, Msg: fmt.Sprintf("operator %s needs a %s, found %s", n.op, want, t)}
+		}
+		return want, lookback, nil
+
+	case *binaryNode:
+		left, leftLookback, err := p.check(n.left)
+		if err != nil {
+			return 0, 0, err
+		}
+		right, rightLookback, err := p.check(n.right)
+		if err != nil {
+			return 0, 0, err
+		}
+		lookback := max(leftLookback, rightLookback)
+
+		switch n.op {
+		case "and", "or":
+			if left != typeBool || right != typeBool {
+				return 0, 0, &Error{Pos: n.pos, Msg: fmt.Sprintf("operator %s needs booleans, found %s and %s", n.op, left, right)}
+			}
+			return typeBool, lookback, nil
+		case "==", "!=":
+			if left != right {
+				return 0, 0, &Error{Pos: n.pos, Msg: fmt.Sprintf("cannot compare %s with %s", left, right)}
+			}
+			return typeBool, lookback, nil
+		case "<", "<=", ">", ">=":
+			if left != typeNumber || right != typeNumber {
+				return 0, 0, &Error{Pos: n.pos, Msg: fmt.Sprintf("operator %s needs numbers, found %s and %s", n.op, left, right)}
+			}
+			return typeBool, lookback, nil
+		default:
+			if left != typeNumber || right != typeNumber {
+				return 0, 0, &Error{Pos: n.pos, Msg: fmt.Sprintf("operator %s needs numbers, found %s and %s", n.op, left, right)}
+			}
+			return typeNumber, lookback, nil
+		}
+
+	case *callNode:
+		fn, ok := functions[n.name]
+		if !ok {
+			return 0, 0, &Error{Pos: n.pos, Msg: fmt.Sprintf("unknown function %q (available: %s)", n.name, strings.Join(Functions(), ", "))}
+		}
+		if len(n.args) != len(fn.params) {
+			return 0, 0, &Error{Pos: n.pos, Msg: fmt.Sprintf("%s takes %d arguments, found %d", n.name, len(fn.params), len(n.args))}
+		}
+
+		consts := make([]float64, 0)
+		series := make([]int, 0)
+		for i, arg := range n.args {
+			switch fn.params[i] {
+			case paramPeriod, paramConstant:
+				num, ok := arg.(*numberNode)
+				if !ok {
+					return 0, 0, &Error{Pos: arg.position(), Msg: fmt.Sprintf("argument %d of %s must be a number literal", i+1, n.name)}
+				}
+				if fn.params[i] == paramPeriod && (num.value < 1 || num.value != math.Trunc(num.value)) {
+					return 0, 0, &Error{Pos: arg.position(), Msg: fmt.Sprintf("argument %d of %s must be a positive whole number", i+1, n.name)}
+				}
+				consts = append(consts, num.value)
+			case paramSeries:
+				t, lookback, err := p.check(arg)
+				if err != nil {
+					return 0, 0, err
+				}
+				if t != typeNumber {
+					return 0, 0, &Error{Pos: arg.position(), Msg: fmt.Sprintf("argument %d of %s must be a number, found %s", i+1, n.name, t)}
+				}
+				series = append(series, lookback)
+			}
+		}
+
+		p.calls = append(p.calls, n)
+		return fn.returns, fn.lookback(consts, series), nil
+	}
+
+	return 0, 0, fmt.Errorf("unknown node %T", n)
+}
+
+// Eval evaluates the rule on the latest bar of s. It returns false while
+// there is not yet enough history for every indicator in the rule.
+func (p *Program) Eval(s *Series) (bool, error) {
+	v, err := p.eval(p.root, s, 0)
+	if errors.Is(err, errNotEnoughData) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return v != 0, nil
+}
+
+// Values evaluates every indicator call in the rule on the latest bar, keyed
+// by the call's source text, e.g. "rsi(14)".
+func (p *Program) Values(s *Series) map[string]float64 {
+	values := make(map[string]float64)
+	for _, call := range p.calls {
+		if functions[call.name].returns != typeNumber {
+			continue
+		}
+		if v, err := p.eval(call, s, 0); err == nil {
+			values[call.src] = v
+		}
+	}
+	return values
+}
+
+func (p *Program) eval(n node, s *Series, shift int) (float64, error) {
+	switch n := n.(type) {
+	case *numberNode:
+		return n.value, nil
+
+	case *boolNode:
+		return boolValue(n.value), nil
+
+	case *identNode:
+		values := identifiers[n.name](s)
+		if len(values)-shift < 1 {
+			return 0, errNotEnoughData
+		}
+		return values[len(values)-1-shift], nil
+
+	case *unaryNode:
+		v, err := p.eval(n.operand, s, shift)
+		if err != nil {
+			return 0, err
+		}
+		if n.op == "not" {
+			return boolValue(v == 0), nil
+		}
+		return -v, nil
+
+	case *binaryNode:
+		left, err := p.eval(n.left, s, shift)
+		if err != nil {
+			return 0, err
+		}
+
+		// Short-circuit so a rule like "false and rsi(14) < 30" does not
+		// wait for rsi history.
+		if n.op == "and" && left == 0 {
+			return 0, nil
+		}
+		if n.op == "or" && left != 0 {
+			return 1, nil
+		}
+
+		right, err := p.eval(n.right, s, shift)
+		if err != nil {
+			return 0, err
+		}
+
+		switch n.op {
+		case "and", "or":
+			return boolValue(right != 0), nil
+		case "<":
+			return boolValue(left < right), nil
+		case "<=":
+			return boolValue(left <= right), nil
+		case ">":
+			return boolValue(left > right), nil
+		case ">=":
+			return boolValue(left >= right), nil
+		case "==":
+			return boolValue(left == right), nil
+		case "!=":
+			return boolValue(left != right), nil
+		case "+":
+			return left + right, nil
+		case "-":
+			return left - right, nil
+		case "*":
+			return left * right, nil
+		case "/":
+			if right == 0 {
+				return 0, &Error{Pos: n.pos, Msg: "division by zero"}
+			}
+			return left / right, nil
+		}
+
+	case *callNode:
+		fn := functions[n.name]
+		consts := make([]float64, 0)
+		series := make([]func(int) (float64, error), 0)
+		for i, arg := range n.args {
+			if fn.params[i] == paramSeries {
+				series = append(series, func(shift int) (float64, error) {
+					return p.eval(arg, s, shift)
+				})
+			} else {
+				consts = append(consts, arg.(*numberNode).value)
+			}
+		}
+		if s.Len()-shift < 1 {
+			return 0, errNotEnoughData
+		}
+		return fn.eval(s, consts, series, shift)
+	}
+
+	return 0, fmt.Errorf("unknown node %T", n)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Functions lists the names of the available functions.
+func Functions() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,124 @@
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		lookback int
+	}{
+		{"comparison", "close > 100", 1},
+		{"keywords are case insensitive", "RSI(14) < 30 AND close > sma(50)", 50},
+		{"precedence", "close - 2 * stddev(20) > 1 or not true", 20},
+		{"parentheses", "(close + open) / 2 >= bb_middle(20, 2)", 20},
+		{"boolean equality", "cross_above(ema(12), ema(26)) == true", 27},
+		{"prev shifts its series", "prev(sma(10), 5) < close", 15},
+		{"rsi needs an extra bar", "rsi(14) > 70", 15},
+		{"unary minus", "-macd_hist(12, 26, 9) > 0.5", 35},
+		{"leading decimal point", "abs(close - open) > .5", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prog, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile(%q) failed: %v", tt.src, err)
+			}
+			if got := prog.Lookback(); got != tt.lookback {
+				t.Errorf("Lookback() = %d, want %d", got, tt.lookback)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		column int
+		msg    string
+	}{
+		{"empty rule", "", 1, "unexpected end of expression"},
+		{"unexpected character", "close > 100 & open", 13, `unexpected character '&'`},
+		{"invalid number", "close > 1.2.3", 9, `invalid number "1.2.3"`},
+		{"unclosed call", "sma(20", 7, "end of expression"},
+		{"trailing tokens", "close > 1 2", 11, `"2"`},
+		{"unknown identifier", "clsoe > 1", 1, `unknown identifier "clsoe"`},
+		{"unknown function", "wma(10) > close", 1, `unknown function "wma"`},
+		{"wrong argument count", "sma(10, 20) > close", 1, "sma takes 1 arguments, found 2"},
+		{"period must be a literal", "sma(close) > 1", 5, "argument 1 of sma must be a number literal"},
+		{"period must be whole", "sma(2.5) > 1", 5, "argument 1 of sma must be a positive whole number"},
+		{"period must be positive", "sma(0) > 1", 5, "argument 1 of sma must be a positive whole number"},
+		{"series must be a number", "abs(close > 1) > 0", 11, "argument 1 of abs must be a number, found boolean"},
+		{"rule must be boolean", "close + 1", 7, "rule must be a boolean expression, found number"},
+		{"and needs booleans", "close and true", 7, "operator and needs booleans, found number and boolean"},
+		{"comparison needs numbers", "true < 1", 6, "operator < needs numbers, found boolean and number"},
+		{"arithmetic needs numbers", "(close > 1) + 1 > 0", 13, "operator + needs numbers, found boolean and number"},
+		{"mixed equality", "close == true", 7, "cannot compare number with boolean"},
+		{"not needs a boolean", "not close", 1, "operator not needs a boolean, found number"},
+		{"minus needs a number", "-true", 1, "operator - needs a number, found boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			if err == nil {
+				t.Fatalf("Compile(%q) succeeded, want an error", tt.src)
+			}
+			var scriptErr *Error
+			if !errors.As(err, &scriptErr) {
+				t.Fatalf("Compile(%q) error %v is a %T, want *Error", tt.src, err, err)
+			}
+			if scriptErr.Pos+1 != tt.column {
+				t.Errorf("error column = %d, want %d (%v)", scriptErr.Pos+1, tt.column, err)
+			}
+			if !strings.Contains(scriptErr.Msg, tt.msg) {
+				t.Errorf("error %q does not mention %q", scriptErr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestEval(t *testing.T) {
+	series := &Series{
+		Open:   []float64{10, 11, 12, 13},
+		High:   []float64{11, 12, 13, 14},
+		Low:    []float64{9, 10, 11, 12},
+		Close:  []float64{11, 12, 13, 12},
+		Volume: []float64{100, 100, 100, 100},
+	}
+
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{"close < prev(close, 1)", true},
+		{"sma(3) > 12.3 and sma(3) < 12.4", true},
+		{"highest(4) >= 13 and lowest(4) <= 11", true},
+		{"cross_below(close, sma(2))", true},
+		{"cross_above(close, sma(2))", false},
+		// Not enough bars yet evaluates to false rather than an error.
+		{"sma(10) > 0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			prog, err := Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile(%q) failed: %v", tt.src, err)
+			}
+			got, err := prog.Eval(series)
+			if err != nil {
+				t.Fatalf("Eval failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"trading-bot/internal/indicator"
+	"trading-bot/internal/market"
+)
+
+type BollingerMode string
+
+const (
+	BollingerMeanReversion BollingerMode = "mean_reversion"
+	BollingerBreakout      BollingerMode = "breakout"
+)
+
+type BollingerConfig struct {
+	Period           int
+	StdDev           float64
+	Mode             BollingerMode
+	Interval         time.Duration
+	VolumePeriod     int
+	VolumeMultiplier float64
+	BuyAmount        float64
+	SellAmount       float64
+}
+
+type BollingerStrategy struct {
+	config        BollingerConfig
+	candles       *market.CandleBuilder
+	closeHistory  []float64
+	volumeHistory []float64
+	maxHistory    int
+	inPosition    bool
+}
+
+func NewBollingerStrategy(config BollingerConfig) *BollingerStrategy {
+	if config.Period <= 0 {
+		config.Period = 20
+	}
+	if config.StdDev <= 0 {
+		config.StdDev = 2.0
+	}
+	if config.Mode == "" {
+		config.Mode = BollingerMeanReversion
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Minute
+	}
+	if config.VolumePeriod <= 0 {
+		config.VolumePeriod = config.Period
+	}
+	if config.BuyAmount <= 0 {
+		config.BuyAmount = 1000.0
+	}
+	if config.SellAmount <= 0 {
+		config.SellAmount = 0.5
+	}
+
+	return &BollingerStrategy{
+		config:        config,
+		candles:       market.NewCandleBuilder(config.Interval),
+		closeHistory:  make([]float64, 0),
+		volumeHistory: make([]float64, 0),
+		maxHistory:    max(config.Period, config.VolumePeriod+1) + 10,
+	}
+}
+
+func (bb *BollingerStrategy) Name() string {
+	return "Bollinger"
+}
+
+func (bb *BollingerStrategy) CandleInterval() time.Duration {
+	return bb.config.Interval
+}
+
+func (bb *BollingerStrategy) SetInPosition(inPosition bool) {
+	bb.inPosition = inPosition
+}
+
+// Analyze aggregates polled ticks into candles and only evaluates the bands
+// when a candle closes.
+func (bb *BollingerStrategy) Analyze(data *market.Data) Signal {
+	candle, closed := bb.candles.Add(data)
+	if !closed {
+		return Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
+	}
+	return bb.AnalyzeCandle(candle)
+}
+
+func (bb *BollingerStrategy) AnalyzeCandle(candle *market.Candle) Signal {
+	bb.closeHistory = append(bb.closeHistory, candle.Close)
+	bb.volumeHistory = append(bb.volumeHistory, candle.Volume)
+
+	if len(bb.closeHistory) > bb.maxHistory {
+		bb.closeHistory = bb.closeHistory[1:]
+		bb.volumeHistory = bb.volumeHistory[1:]
+	}
+
+	if len(bb.closeHistory) < bb.config.Period {
+		return Signal{Action: ActionHold, Symbol: candle.Symbol, Amount: 0}
+	}
+
+	upper, middle, lower := indicator.BollingerBands(bb.closeHistory, bb.config.Period, bb.config.StdDev)
+	indicators := map[string]float64{
+		"upper":  upper,
+		"middle": middle,
+		"lower":  lower,
+		"close":  candle.Close,
+		"volume": candle.Volume,
+	}
+
+	if !bb.inPosition {
+		if signal, ok := bb.entrySignal(candle, upper, middle, lower); ok {
+			bb.inPosition = true
+			signal.Indicators = indicators
+			return signal
+		}
+	}
+
+	if bb.inPosition {
+		exit := candle.Close >= middle
+		reason := fmt.Sprintf("close %.2f reached middle band %.2f", candle.Close, middle)
+		if bb.config.Mode == BollingerBreakout {
+			exit = candle.Close < middle
+			reason = fmt.Sprintf("close %.2f fell back below middle band %.2f", candle.Close, middle)
+		}
+
+		if exit {
+			bb.inPosition = false
+			return Signal{
+				Action:     ActionSell,
+				Symbol:     "BTC",
+				Amount:     bb.config.SellAmount,
+				Confidence: 0.5,
+				Reason:     reason,
+				Indicators: indicators,
+			}
+		}
+	}
+
+	return Signal{Action: ActionHold, Symbol: candle.Symbol, Amount: 0}
+}
+
+// entrySignal checks the mode's entry rule. Confidence grows with how far the
+// close is beyond the band, reaching 1 at half a band width; the stop hint
+// sits half a band width below the lower band for mean reversion and at the
+// middle band for breakouts.
+func (bb *BollingerStrategy) entrySignal(candle *market.Candle, upper, middle, lower float64) (Signal, bool) {
+	halfWidth := (upper - lower) / 2
+	if halfWidth <= 0 {
+		return Signal{}, false
+	}
+
+	if bb.config.Mode == BollingerBreakout {
+		if candle.Close <= upper || !bb.volumeConfirmed(candle.Volume) {
+			return Signal{}, false
+		}
+		return Signal{
+			Action:     ActionBuy,
+			Symbol:     "BTC",
+			Amount:     bb.config.BuyAmount,
+			Confidence: clampConfidence(0.5 + (candle.Close-upper)/halfWidth),
+			Reason:     fmt.Sprintf("close %.2f broke above upper band %.2f on volume %.2f", candle.Close, upper, candle.Volume),
+			StopPrice:  middle,
+		}, true
+	}
+
+	if candle.Close > lower {
+		return Signal{}, false
+	}
+	return Signal{
+		Action:     ActionBuy,
+		Symbol:     "BTC",
+		Amount:     bb.config.BuyAmount,
+		Confidence: clampConfidence(0.5 + (lower-candle.Close)/halfWidth),
+		Reason:     fmt.Sprintf("close %.2f at or below lower band %.2f", candle.Close, lower),
+		StopPrice:  lower - halfWidth,
+	}, true
+}
+
+// NeedsVolume reports whether breakouts are confirmed on volume.
+func (bb *BollingerStrategy) NeedsVolume() bool {
+	return bb.config.Mode == BollingerBreakout && bb.config.VolumeMultiplier > 0
+}
+
+// volumeConfirmed compares the breakout candle's volume against the average
+// of the candles before it. A non-positive multiplier disables the check; a
+// candle without volume never confirms.
+func (bb *BollingerStrategy) volumeConfirmed(volume float64) bool {
+	if bb.config.VolumeMultiplier <= 0 {
+		return true
+	}
+	if volume <= 0 {
+		return false
+	}
+
+	previous := bb.volumeHistory[:len(bb.volumeHistory)-1]
+	if len(previous) < bb.config.VolumePeriod {
+		return false
+	}
+
+	return volume > indicator.SMA(previous, bb.config.VolumePeriod)*bb.config.VolumeMultiplier
+}
+
+type bollingerState struct {
+	CloseHistory  []float64 `json:"close_history"`
+	VolumeHistory []float64 `json:"volume_history"`
+	InPosition    bool      `json:"in_position"`
+}
+
+func (bb *BollingerStrategy) WarmUp(candles []market.Candle) {
+	for _, candle := range candles {
+		bb.closeHistory = trimHistory(append(bb.closeHistory, candle.Close), bb.maxHistory)
+		bb.volumeHistory = trimHistory(append(bb.volumeHistory, candle.Volume), bb.maxHistory)
+	}
+}
+
+func (bb *BollingerStrategy) SnapshotState() ([]byte, error) {
+	return json.Marshal(bollingerState{
+		CloseHistory:  bb.closeHistory,
+		VolumeHistory: bb.volumeHistory,
+		InPosition:    bb.inPosition,
+	})
+}
+
+func (bb *BollingerStrategy) RestoreState(data []byte) error {
+	var state bollingerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if len(state.CloseHistory) != len(state.VolumeHistory) {
+		return fmt.Errorf("close and volume history lengths differ")
+	}
+
+	bb.closeHistory = trimHistory(state.CloseHistory, bb.maxHistory)
+	bb.volumeHistory = trimHistory(state.VolumeHistory, bb.maxHistory)
+	bb.inPosition = state.InPosition
+	return nil
+}
@@ -0,0 +1,379 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"trading-bot/internal/market"
+)
+
+type CombineRule string
+
+const (
+	RuleAll         CombineRule = "all"
+	RuleAny         CombineRule = "any"
+	RuleMajority    CombineRule = "majority"
+	RuleWeighted    CombineRule = "weighted"
+	RuleTrendFilter CombineRule = "trend_filter"
+)
+
+type ChildRole string
+
+const (
+	RoleTrigger ChildRole = "trigger"
+	RoleFilter  ChildRole = "filter"
+)
+
+type CompositeChild struct {
+	Strategy Strategy
+	Weight   float64
+	Role     ChildRole
+}
+
+type CompositeStrategy struct {
+	rule       CombineRule
+	threshold  float64
+	children   []CompositeChild
+	trends     []Action
+	inPosition bool
+}
+
+// NewCompositeStrategy combines child signals with the given rule. For
+// RuleWeighted the threshold is the fraction of the total weight that must
+// vote the same way; it defaults to one half.
+func NewCompositeStrategy(rule CombineRule, threshold float64, children []CompositeChild) *CompositeStrategy {
+	if rule == "" {
+		rule = RuleAll
+	}
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+
+	trends := make([]Action, len(children))
+	for i := range children {
+		if children[i].Weight <= 0 {
+			children[i].Weight = 1.0
+		}
+		if children[i].Role == "" {
+			children[i].Role = RoleTrigger
+		}
+		trends[i] = ActionHold
+	}
+
+	return &CompositeStrategy{
+		rule:      rule,
+		threshold: threshold,
+		children:  children,
+		trends:    trends,
+	}
+}
+
+func (cs *CompositeStrategy) Name() string {
+	return "Composite"
+}
+
+func (cs *CompositeStrategy) Children() []CompositeChild {
+	return cs.children
+}
+
+func (cs *CompositeStrategy) Timeframes() []string {
+	seen := make(map[string]bool)
+	timeframes := make([]string, 0)
+	for _, child := range cs.children {
+		mtf, ok := child.Strategy.(MultiTimeframe)
+		if !ok {
+			continue
+		}
+		for _, tf := range mtf.Timeframes() {
+			if !seen[tf] {
+				seen[tf] = true
+				timeframes = append(timeframes, tf)
+			}
+		}
+	}
+	return timeframes
+}
+
+func (cs *CompositeStrategy) NeedsVolume() bool {
+	for _, child := range cs.children {
+		if v, ok := child.Strategy.(VolumeStrategy); ok && v.NeedsVolume() {
+			return true
+		}
+	}
+	return false
+}
+
+func (cs *CompositeStrategy) SetInPosition(inPosition bool) {
+	cs.inPosition = inPosition
+	cs.syncPosition()
+}
+
+func (cs *CompositeStrategy) Analyze(data *market.Data) Signal {
+	return cs.analyze(data, func(child Strategy) Signal {
+		return child.Analyze(data)
+	})
+}
+
+// AnalyzeContext passes the context on to every child, so context-aware
+// children see the same account state as the composite.
+func (cs *CompositeStrategy) AnalyzeContext(ctx *AnalysisContext) Signal {
+	cs.inPosition = ctx.InPosition()
+	return cs.analyze(ctx.Data, func(child Strategy) Signal {
+		return Adapt(child).AnalyzeContext(ctx)
+	})
+}
+
+func (cs *CompositeStrategy) analyze(data *market.Data, analyzeChild func(Strategy) Signal) Signal {
+	signals := make([]Signal, len(cs.children))
+	for i, child := range cs.children {
+		signals[i] = analyzeChild(child.Strategy)
+		cs.updateTrend(i, signals[i])
+	}
+
+	signal := cs.combine(data, signals)
+
+	switch signal.Action {
+	case ActionBuy:
+		cs.inPosition = true
+	case ActionSell:
+		cs.inPosition = false
+	}
+	// Children that track their own position must follow the composite's
+	// decision rather than the signals they emitted themselves.
+	cs.syncPosition()
+
+	return signal
+}
+
+func (cs *CompositeStrategy) combine(data *market.Data, signals []Signal) Signal {
+	hold := Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
+
+	switch cs.rule {
+	case RuleTrendFilter:
+		direction := cs.filterDirection()
+		if direction == ActionHold {
+			return hold
+		}
+		return cs.result(hold, direction, signals, func(i int) bool {
+			return cs.children[i].Role != RoleFilter
+		})
+
+	case RuleWeighted:
+		score, total := 0.0, 0.0
+		for i, s := range signals {
+			total += cs.children[i].Weight
+			switch s.Action {
+			case ActionBuy:
+				score += cs.children[i].Weight
+			case ActionSell:
+				score -= cs.children[i].Weight
+			}
+		}
+		if total == 0 {
+			return hold
+		}
+		if score/total >= cs.threshold {
+			return cs.result(hold, ActionBuy, signals, nil)
+		}
+		if -score/total >= cs.threshold {
+			return cs.result(hold, ActionSell, signals, nil)
+		}
+		return hold
+	}
+
+	buys, sells := 0, 0
+	for _, s := range signals {
+		switch s.Action {
+		case ActionBuy:
+			buys++
+		case ActionSell:
+			sells++
+		}
+	}
+
+	var action Action
+	switch cs.rule {
+	case RuleAny:
+		if buys > 0 && sells == 0 {
+			action = ActionBuy
+		} else if sells > 0 && buys == 0 {
+			action = ActionSell
+		}
+	case RuleMajority:
+		if buys*2 > len(signals) {
+			action = ActionBuy
+		} else if sells*2 > len(signals) {
+			action = ActionSell
+		}
+	default:
+		if len(signals) > 0 && buys == len(signals) {
+			action = ActionBuy
+		} else if len(signals) > 0 && sells == len(signals) {
+			action = ActionSell
+		}
+	}
+
+	if action == "" {
+		return hold
+	}
+	return cs.result(hold, action, signals, nil)
+}
+
+// result builds the combined signal from the children that voted for action,
+// averaging their amounts and confidences and merging their reasons and
+// indicators. The voters, plus any filters that allowed the trade, are
+// recorded as the signal's sources.
+func (cs *CompositeStrategy) result(hold Signal, action Action, signals []Signal, eligible func(int) bool) Signal {
+	combined := Signal{Action: action, Indicators: make(map[string]float64)}
+	amount, confidence, voters := 0.0, 0.0, 0
+	reasons := make([]string, 0)
+
+	for i, s := range signals {
+		if s.Action != action || (eligible != nil && !eligible(i)) {
+			continue
+		}
+
+		name := cs.children[i].Strategy.Name()
+		if combined.Symbol == "" {
+			combined.Symbol = s.Symbol
+		}
+		amount += s.Amount
+		confidence += s.Confidence
+		voters++
+		combined.Sources = append(combined.Sources, name)
+
+		if s.Reason != "" {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", name, s.Reason))
+		}
+		for key, value := range s.Indicators {
+			combined.Indicators[name+"."+key] = value
+		}
+		if combined.OrderType == "" && s.OrderType != "" {
+			combined.OrderType, combined.LimitPrice = s.OrderType, s.LimitPrice
+		}
+		if combined.StopPrice == 0 {
+			combined.StopPrice = s.StopPrice
+		}
+	}
+
+	if voters == 0 {
+		return hold
+	}
+	combined.Amount = amount / float64(voters)
+	combined.Confidence = confidence / float64(voters)
+
+	trend := "uptrend"
+	if action == ActionSell {
+		trend = "downtrend"
+	}
+	for i, child := range cs.children {
+		if eligible != nil && !eligible(i) {
+			combined.Sources = append(combined.Sources, child.Strategy.Name())
+			reasons = append(reasons, fmt.Sprintf("%s: %s", child.Strategy.Name(), trend))
+		}
+	}
+
+	combined.Reason = strings.Join(reasons, "; ")
+	return combined
+}
+
+// filterDirection returns the trend all filter children agree on, or hold if
+// they disagree or have no trend yet.
+func (cs *CompositeStrategy) filterDirection() Action {
+	direction := ActionHold
+	for i, child := range cs.children {
+		if child.Role != RoleFilter {
+			continue
+		}
+		if cs.trends[i] == ActionHold || (direction != ActionHold && cs.trends[i] != direction) {
+			return ActionHold
+		}
+		direction = cs.trends[i]
+	}
+	return direction
+}
+
+func (cs *CompositeStrategy) updateTrend(i int, signal Signal) {
+	if reporter, ok := cs.children[i].Strategy.(TrendReporter); ok {
+		cs.trends[i] = reporter.Trend()
+		return
+	}
+	if signal.Action != ActionHold {
+		cs.trends[i] = signal.Action
+	}
+}
+
+func (cs *CompositeStrategy) syncPosition() {
+	for _, child := range cs.children {
+		if tracker, ok := child.Strategy.(PositionTracker); ok {
+			tracker.SetInPosition(cs.inPosition)
+		}
+	}
+}
+
+type compositeState struct {
+	InPosition bool              `json:"in_position"`
+	Trends     []Action          `json:"trends"`
+	Children   []json.RawMessage `json:"children"`
+}
+
+func (cs *CompositeStrategy) WarmUp(candles []market.Candle) {
+	for i, child := range cs.children {
+		WarmUp(child.Strategy, candles)
+		if reporter, ok := child.Strategy.(TrendReporter); ok {
+			cs.trends[i] = reporter.Trend()
+		}
+	}
+	cs.syncPosition()
+}
+
+// SnapshotState stores each child's state by position; children that do not
+// support snapshots are recorded as null and start cold on restore.
+func (cs *CompositeStrategy) SnapshotState() ([]byte, error) {
+	state := compositeState{
+		InPosition: cs.inPosition,
+		Trends:     cs.trends,
+		Children:   make([]json.RawMessage, len(cs.children)),
+	}
+
+	for i, child := range cs.children {
+		stateful, ok := child.Strategy.(Stateful)
+		if !ok {
+			state.Children[i] = json.RawMessage("null")
+			continue
+		}
+		data, err := stateful.SnapshotState()
+		if err != nil {
+			return nil, fmt.Errorf("child %s: %w", child.Strategy.Name(), err)
+		}
+		state.Children[i] = data
+	}
+
+	return json.Marshal(state)
+}
+
+func (cs *CompositeStrategy) RestoreState(data []byte) error {
+	var state compositeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if len(state.Children) != len(cs.children) || len(state.Trends) != len(cs.children) {
+		return fmt.Errorf("snapshot has %d children, strategy has %d", len(state.Children), len(cs.children))
+	}
+
+	for i, child := range cs.children {
+		stateful, ok := child.Strategy.(Stateful)
+		if !ok || string(state.Children[i]) == "null" {
+			continue
+		}
+		if err := stateful.RestoreState(state.Children[i]); err != nil {
+			return fmt.Errorf("child %s: %w", child.Strategy.Name(), err)
+		}
+	}
+
+	cs.inPosition = state.InPosition
+	copy(cs.trends, state.Trends)
+	cs.syncPosition()
+	return nil
+}
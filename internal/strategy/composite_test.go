@@ -0,0 +1,113 @@
+package strategy
+
+import (
+	"slices"
+	"testing"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/market"
+)
+
+// fixedStrategy always signals action.
+type fixedStrategy struct {
+	name   string
+	action Action
+}
+
+func (f fixedStrategy) Name() string { return f.name }
+
+func (f fixedStrategy) Analyze(data *market.Data) Signal {
+	if f.action == ActionHold {
+		return Signal{Action: ActionHold, Symbol: data.Symbol}
+	}
+	return Signal{Action: f.action, Symbol: "BTC", Amount: 1, Confidence: 0.5, Reason: f.name}
+}
+
+func TestCompositeRules(t *testing.T) {
+	child := func(name string, action Action) CompositeChild {
+		return CompositeChild{Strategy: fixedStrategy{name: name, action: action}}
+	}
+
+	tests := []struct {
+		name      string
+		rule      CombineRule
+		threshold float64
+		children  []CompositeChild
+		want      Action
+		sources   []string
+	}{
+		{name: "all agree", rule: RuleAll, children: []CompositeChild{child("a", ActionBuy), child("b", ActionBuy)}, want: ActionBuy, sources: []string{"a", "b"}},
+		{name: "all with a hold", rule: RuleAll, children: []CompositeChild{child("a", ActionBuy), child("b", ActionHold)}, want: ActionHold},
+		{name: "any with a hold", rule: RuleAny, children: []CompositeChild{child("a", ActionHold), child("b", ActionSell)}, want: ActionSell, sources: []string{"b"}},
+		{name: "any disagreeing", rule: RuleAny, children: []CompositeChild{child("a", ActionBuy), child("b", ActionSell)}, want: ActionHold},
+		{name: "majority", rule: RuleMajority, children: []CompositeChild{child("a", ActionBuy), child("b", ActionBuy), child("c", ActionSell)}, want: ActionBuy, sources: []string{"a", "b"}},
+		{name: "no majority", rule: RuleMajority, children: []CompositeChild{child("a", ActionBuy), child("b", ActionHold), child("c", ActionSell)}, want: ActionHold},
+		{
+			name: "weight reaching the threshold", rule: RuleWeighted, threshold: 0.6,
+			children: []CompositeChild{
+				{Strategy: fixedStrategy{name: "a", action: ActionBuy}, Weight: 2},
+				{Strategy: fixedStrategy{name: "b", action: ActionHold}, Weight: 1},
+			},
+			want: ActionBuy, sources: []string{"a"},
+		},
+		{
+			name: "weight short of the threshold", rule: RuleWeighted, threshold: 0.6,
+			children: []CompositeChild{
+				{Strategy: fixedStrategy{name: "a", action: ActionHold}, Weight: 2},
+				{Strategy: fixedStrategy{name: "b", action: ActionBuy}, Weight: 1},
+			},
+			want: ActionHold,
+		},
+		{
+			name: "trigger in the filter's direction", rule: RuleTrendFilter,
+			children: []CompositeChild{
+				{Strategy: fixedStrategy{name: "trend", action: ActionBuy}, Role: RoleFilter},
+				{Strategy: fixedStrategy{name: "entry", action: ActionBuy}, Role: RoleTrigger},
+			},
+			want: ActionBuy, sources: []string{"entry", "trend"},
+		},
+		{
+			name: "trigger against the filter", rule: RuleTrendFilter,
+			children: []CompositeChild{
+				{Strategy: fixedStrategy{name: "trend", action: ActionSell}, Role: RoleFilter},
+				{Strategy: fixedStrategy{name: "entry", action: ActionBuy}, Role: RoleTrigger},
+			},
+			want: ActionHold,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := NewCompositeStrategy(tt.rule, tt.threshold, tt.children)
+			signal := cs.Analyze(&market.Data{Symbol: "BTCUSDT", Price: decimal.MustParse("50000")})
+
+			if signal.Action != tt.want {
+				t.Fatalf("action = %s, want %s", signal.Action, tt.want)
+			}
+			if !slices.Equal(signal.Sources, tt.sources) {
+				t.Errorf("sources = %v, want %v", signal.Sources, tt.sources)
+			}
+		})
+	}
+}
+
+func TestCompositeSyncsChildPositions(t *testing.T) {
+	ma := NewMovingAverageStrategyWithConfig(MovingAverageConfig{ShortPeriod: 2, LongPeriod: 4, PositionAware: true})
+	cs := NewCompositeStrategy(RuleAll, 0, []CompositeChild{
+		{Strategy: fixedStrategy{name: "entry", action: ActionBuy}},
+		{Strategy: ma},
+	})
+
+	// The moving average holds, so the composite does not buy, and the
+	// child's own idea of its position gives way to the composite's.
+	ma.SetInPosition(true)
+	cs.Analyze(&market.Data{Symbol: "BTCUSDT", Price: decimal.MustParse("50000")})
+	if ma.InPosition() {
+		t.Error("child is in a position the composite never entered")
+	}
+
+	cs.SetInPosition(true)
+	if !ma.InPosition() {
+		t.Error("child did not follow the composite into a position")
+	}
+}
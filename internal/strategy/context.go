@@ -0,0 +1,76 @@
+package strategy
+
+import (
+	"time"
+
+	"trading-bot/internal/market"
+)
+
+type OpenOrder struct {
+	ID        int64
+	Symbol    string
+	Side      Action
+	Type      OrderType
+	Quantity  float64
+	Price     float64
+	CreatedAt time.Time
+}
+
+type Fill struct {
+	Timestamp time.Time
+	Side      Action
+	Symbol    string
+	Quantity  float64
+	Price     float64
+}
+
+// AnalysisContext is everything a strategy may consult when deciding: the
+// latest tick, recent ticks, closed bars for each timeframe the strategy
+// declared, the account's state for the traded asset and the current time,
+// which is the replayed time during backtests.
+type AnalysisContext struct {
+	Data       *market.Data
+	History    []market.Data
+	Bars       map[string][]market.Candle
+	Asset      string
+	Position   float64
+	Cash       float64
+	Equity     float64
+	OpenOrders []OpenOrder
+	Fills      []Fill
+	Now        time.Time
+}
+
+func (c *AnalysisContext) InPosition() bool {
+	return c.Position > 0
+}
+
+type MultiTimeframe interface {
+	Timeframes() []string
+}
+
+type ContextStrategy interface {
+	AnalyzeContext(ctx *AnalysisContext) Signal
+	Name() string
+}
+
+type strategyAdapter struct {
+	Strategy
+}
+
+// Adapt lets a plain Strategy be driven with an AnalysisContext. Strategies
+// that track their own position are synced with the real position before
+// each analysis.
+func Adapt(s Strategy) ContextStrategy {
+	if cs, ok := s.(ContextStrategy); ok {
+		return cs
+	}
+	return &strategyAdapter{Strategy: s}
+}
+
+func (a *strategyAdapter) AnalyzeContext(ctx *AnalysisContext) Signal {
+	if tracker, ok := a.Strategy.(PositionTracker); ok {
+		tracker.SetInPosition(ctx.InPosition())
+	}
+	return a.Strategy.Analyze(ctx.Data)
+}
@@ -0,0 +1,259 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"trading-bot/internal/indicator"
+	"trading-bot/internal/market"
+)
+
+// MACDConfig configures the MACD strategy. HistogramFilter only acts on
+// crosses where the histogram changed sign from the previous bar to this one,
+// not on a cross measured against a relation remembered from before a flat
+// bar, a gap or a restart.
+type MACDConfig struct {
+	FastPeriod         int
+	SlowPeriod         int
+	SignalPeriod       int
+	HistogramFilter    bool
+	DivergenceFilter   bool
+	DivergenceLookback int
+	BuyAmount          float64
+	SellAmount         float64
+}
+
+type MACDStrategy struct {
+	config       MACDConfig
+	priceHistory []float64
+	maxHistory   int
+	relation     int
+	histogram    float64
+}
+
+func NewMACDStrategy(config MACDConfig) *MACDStrategy {
+	if config.FastPeriod <= 0 {
+		config.FastPeriod = 12
+	}
+	if config.SlowPeriod <= 0 {
+		config.SlowPeriod = 26
+	}
+	if config.SignalPeriod <= 0 {
+		config.SignalPeriod = 9
+	}
+	if config.DivergenceLookback <= 0 {
+		config.DivergenceLookback = 20
+	}
+	if config.BuyAmount <= 0 {
+		config.BuyAmount = 1000.0
+	}
+	if config.SellAmount <= 0 {
+		config.SellAmount = 0.5
+	}
+
+	return &MACDStrategy{
+		config:       config,
+		priceHistory: make([]float64, 0),
+		maxHistory:   emaHistory(config.SlowPeriod) + config.SignalPeriod + config.DivergenceLookback,
+	}
+}
+
+func (m *MACDStrategy) Name() string {
+	return "MACD"
+}
+
+func (m *MACDStrategy) Trend() Action {
+	switch m.relation {
+	case 1:
+		return ActionBuy
+	case -1:
+		return ActionSell
+	}
+	return ActionHold
+}
+
+func (m *MACDStrategy) Analyze(data *market.Data) Signal {
+	m.priceHistory = append(m.priceHistory, data.Price.Float64())
+
+	if len(m.priceHistory) > m.maxHistory {
+		m.priceHistory = m.priceHistory[1:]
+	}
+
+	macdLine, signalLine := m.calculateMACD()
+	if len(signalLine) == 0 {
+		return Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
+	}
+
+	relation := histogramRelation(macdLine, signalLine)
+	previous, previousHistogram := m.relation, m.histogram
+	if relation != 0 {
+		m.relation = relation
+	}
+	m.histogram = macdLine[len(macdLine)-1] - signalLine[len(signalLine)-1]
+	if previous == 0 || relation == 0 || relation == previous {
+		return Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
+	}
+
+	macd := macdLine[len(macdLine)-1]
+	signal := signalLine[len(signalLine)-1]
+	divergence := m.divergence(macdLine)
+	indicators := map[string]float64{
+		"macd":      macd,
+		"signal":    signal,
+		"histogram": macd - signal,
+	}
+
+	action, direction := ActionBuy, "above"
+	if relation < 0 {
+		action, direction = ActionSell, "below"
+	}
+
+	// A cross on the trend side of the zero line and one backed by a
+	// divergence each add to the base confidence.
+	trendSide := (relation > 0 && macd > 0) || (relation < 0 && macd < 0)
+	divergent := divergence == relation
+	signChanged := previousHistogram*m.histogram < 0
+	if (m.config.HistogramFilter && !signChanged) || (m.config.DivergenceFilter && !divergent) {
+		return Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
+	}
+
+	confidence := 0.5
+	reason := fmt.Sprintf("MACD %.2f crossed %s signal %.2f", macd, direction, signal)
+	if trendSide {
+		confidence += 0.25
+		reason += fmt.Sprintf(", %s zero line", direction)
+	}
+	if divergent {
+		confidence += 0.25
+		reason += ", confirmed by divergence"
+	}
+
+	amount := m.config.BuyAmount
+	if action == ActionSell {
+		amount = m.config.SellAmount
+	}
+
+	return Signal{
+		Action:     action,
+		Symbol:     "BTC",
+		Amount:     amount,
+		Confidence: confidence,
+		Reason:     reason,
+		Indicators: indicators,
+	}
+}
+
+// calculateMACD returns the MACD line and its signal line, both aligned so
+// their last elements refer to the latest price.
+func (m *MACDStrategy) calculateMACD() ([]float64, []float64) {
+	if len(m.priceHistory) < m.config.SlowPeriod+m.config.SignalPeriod-1 {
+		return nil, nil
+	}
+
+	fast := indicator.EMASeries(m.priceHistory, m.config.FastPeriod)
+	slow := indicator.EMASeries(m.priceHistory, m.config.SlowPeriod)
+	fast = fast[len(fast)-len(slow):]
+
+	macdLine := make([]float64, len(slow))
+	for i := range slow {
+		macdLine[i] = fast[i] - slow[i]
+	}
+
+	return macdLine, indicator.EMASeries(macdLine, m.config.SignalPeriod)
+}
+
+func histogramRelation(macdLine, signalLine []float64) int {
+	histogram := macdLine[len(macdLine)-1] - signalLine[len(signalLine)-1]
+	if histogram > 0 {
+		return 1
+	} else if histogram < 0 {
+		return -1
+	}
+	return 0
+}
+
+// divergence compares the two halves of the lookback window and returns 1 for
+// a bullish divergence (lower price low, higher MACD low), -1 for a bearish
+// one (higher price high, lower MACD high) and 0 otherwise.
+func (m *MACDStrategy) divergence(macdLine []float64) int {
+	lookback := m.config.DivergenceLookback
+	if len(macdLine) < lookback || len(m.priceHistory) < lookback {
+		return 0
+	}
+
+	prices := m.priceHistory[len(m.priceHistory)-lookback:]
+	macd := macdLine[len(macdLine)-lookback:]
+	half := lookback / 2
+
+	firstLow, secondLow := argMin(prices[:half]), half+argMin(prices[half:])
+	if prices[secondLow] < prices[firstLow] && macd[secondLow] > macd[firstLow] {
+		return 1
+	}
+
+	firstHigh, secondHigh := argMax(prices[:half]), half+argMax(prices[half:])
+	if prices[secondHigh] > prices[firstHigh] && macd[secondHigh] < macd[firstHigh] {
+		return -1
+	}
+
+	return 0
+}
+
+func argMin(values []float64) int {
+	idx := 0
+	for i, v := range values {
+		if v < values[idx] {
+			idx = i
+		}
+	}
+	return idx
+}
+
+func argMax(values []float64) int {
+	idx := 0
+	for i, v := range values {
+		if v > values[idx] {
+			idx = i
+		}
+	}
+	return idx
+}
+
+type macdState struct {
+	PriceHistory []float64 `json:"price_history"`
+	Relation     int       `json:"relation"`
+	Histogram    float64   `json:"histogram"`
+}
+
+func (m *MACDStrategy) WarmUp(candles []market.Candle) {
+	for _, candle := range candles {
+		m.priceHistory = trimHistory(append(m.priceHistory, candle.Close), m.maxHistory)
+		macdLine, signalLine := m.calculateMACD()
+		if len(signalLine) == 0 {
+			continue
+		}
+		if relation := histogramRelation(macdLine, signalLine); relation != 0 {
+			m.relation = relation
+		}
+		m.histogram = macdLine[len(macdLine)-1] - signalLine[len(signalLine)-1]
+	}
+}
+
+func (m *MACDStrategy) SnapshotState() ([]byte, error) {
+	return json.Marshal(macdState{
+		PriceHistory: m.priceHistory,
+		Relation:     m.relation,
+		Histogram:    m.histogram,
+	})
+}
+
+func (m *MACDStrategy) RestoreState(data []byte) error {
+	var state macdState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	m.priceHistory = trimHistory(state.PriceHistory, m.maxHistory)
+	m.relation = state.Relation
+	m.histogram = state.Histogram
+	return nil
+}
@@ -0,0 +1,45 @@
+package strategy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/market"
+)
+
+func TestMACDHistogramFilter(t *testing.T) {
+	flat := make([]float64, 40)
+	for i := range flat {
+		flat[i] = 100
+	}
+
+	tests := []struct {
+		name      string
+		filter    bool
+		histogram float64
+		want      Action
+	}{
+		{name: "unfiltered cross against a remembered relation", filter: false, histogram: 0, want: ActionBuy},
+		{name: "filtered cross without a sign change", filter: true, histogram: 0, want: ActionHold},
+		{name: "filtered cross with a sign change", filter: true, histogram: -0.5, want: ActionBuy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMACDStrategy(MACDConfig{HistogramFilter: tt.filter})
+			state, err := json.Marshal(macdState{PriceHistory: flat, Relation: -1, Histogram: tt.histogram})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.RestoreState(state); err != nil {
+				t.Fatal(err)
+			}
+
+			signal := m.Analyze(&market.Data{Symbol: "BTCUSDT", Price: decimal.MustParse("110")})
+			if signal.Action != tt.want {
+				t.Errorf("action = %s, want %s", signal.Action, tt.want)
+			}
+		})
+	}
+}
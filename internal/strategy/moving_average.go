@@ -1,22 +1,87 @@
 package strategy
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"trading-bot/internal/indicator"
 	"trading-bot/internal/market"
 )
 
+type MAType string
+
+const (
+	MATypeSMA MAType = "sma"
+	MATypeEMA MAType = "ema"
+)
+
+type CrossEvent string
+
+const (
+	CrossNone   CrossEvent = "NONE"
+	CrossGolden CrossEvent = "GOLDEN_CROSS"
+	CrossDeath  CrossEvent = "DEATH_CROSS"
+)
+
+type MovingAverageConfig struct {
+	ShortPeriod      int
+	LongPeriod       int
+	Type             MAType
+	ConfirmationBars int
+	PositionAware    bool
+	BuyAmount        float64
+	SellAmount       float64
+}
+
 type MovingAverageStrategy struct {
-	shortPeriod  int
-	longPeriod   int
+	config       MovingAverageConfig
 	priceHistory []float64
 	maxHistory   int
+
+	relation    int
+	pending     CrossEvent
+	pendingBars int
+	lastCross   CrossEvent
+	inPosition  bool
 }
 
 func NewMovingAverageStrategy(shortPeriod, longPeriod int) Strategy {
+	return NewMovingAverageStrategyWithConfig(MovingAverageConfig{
+		ShortPeriod: shortPeriod,
+		LongPeriod:  longPeriod,
+	})
+}
+
+func NewMovingAverageStrategyWithConfig(config MovingAverageConfig) *MovingAverageStrategy {
+	if config.ShortPeriod <= 0 {
+		config.ShortPeriod = 20
+	}
+	if config.LongPeriod <= 0 {
+		config.LongPeriod = 50
+	}
+	if config.Type == "" {
+		config.Type = MATypeSMA
+	}
+	if config.BuyAmount <= 0 {
+		config.BuyAmount = 1000.0
+	}
+	if config.SellAmount <= 0 {
+		config.SellAmount = 0.5
+	}
+
+	maxHistory := config.LongPeriod + 10
+	if config.Type == MATypeEMA {
+		maxHistory = emaHistory(config.LongPeriod)
+	}
+
 	return &MovingAverageStrategy{
-		shortPeriod:  shortPeriod,
-		longPeriod:   longPeriod,
+		config:       config,
 		priceHistory: make([]float64, 0),
-		maxHistory:   longPeriod + 10,
+		maxHistory:   maxHistory,
+		pending:      CrossNone,
+		lastCross:    CrossNone,
 	}
 }
 
@@ -24,47 +89,187 @@ func (mas *MovingAverageStrategy) Name() string {
 	return "MovingAverage"
 }
 
+func (mas *MovingAverageStrategy) LastCross() CrossEvent {
+	return mas.lastCross
+}
+
+func (mas *MovingAverageStrategy) Trend() Action {
+	switch mas.relation {
+	case 1:
+		return ActionBuy
+	case -1:
+		return ActionSell
+	}
+	return ActionHold
+}
+
+func (mas *MovingAverageStrategy) InPosition() bool {
+	return mas.inPosition
+}
+
+func (mas *MovingAverageStrategy) SetInPosition(inPosition bool) {
+	mas.inPosition = inPosition
+}
+
 func (mas *MovingAverageStrategy) Analyze(data *market.Data) Signal {
-	mas.priceHistory = append(mas.priceHistory, data.Price)
+	mas.priceHistory = append(mas.priceHistory, data.Price.Float64())
 
 	if len(mas.priceHistory) > mas.maxHistory {
 		mas.priceHistory = mas.priceHistory[1:]
 	}
 
-	if len(mas.priceHistory) < mas.longPeriod {
+	if len(mas.priceHistory) < mas.config.LongPeriod {
 		return Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
 	}
 
-	shortMA := mas.calculateMA(mas.shortPeriod)
-	longMA := mas.calculateMA(mas.longPeriod)
+	shortMA := mas.calculateMA(mas.config.ShortPeriod)
+	longMA := mas.calculateMA(mas.config.LongPeriod)
+	indicators := map[string]float64{"short_ma": shortMA, "long_ma": longMA}
 
-	if shortMA > longMA {
+	// Confidence grows with the gap between the averages: a 1% spread is
+	// treated as a fully confident cross.
+	confidence := clampConfidence(0.5 + math.Abs(shortMA-longMA)/longMA*50)
+
+	switch event := mas.detectCross(shortMA, longMA); event {
+	case CrossGolden:
+		if mas.config.PositionAware && mas.inPosition {
+			break
+		}
+		mas.inPosition = true
 		return Signal{
-			Action: ActionBuy,
-			Symbol: "BTC",
-			Amount: 1000.0,
+			Action:     ActionBuy,
+			Symbol:     "BTC",
+			Amount:     mas.config.BuyAmount,
+			Confidence: confidence,
+			Reason:     mas.crossReason(event, shortMA, longMA),
+			Indicators: indicators,
 		}
-	} else if shortMA < longMA {
+	case CrossDeath:
+		if mas.config.PositionAware && !mas.inPosition {
+			break
+		}
+		mas.inPosition = false
 		return Signal{
-			Action: ActionSell,
-			Symbol: "BTC",
-			Amount: 0.5,
+			Action:     ActionSell,
+			Symbol:     "BTC",
+			Amount:     mas.config.SellAmount,
+			Confidence: confidence,
+			Reason:     mas.crossReason(event, shortMA, longMA),
+			Indicators: indicators,
 		}
 	}
 
 	return Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
 }
 
+// detectCross returns a cross event once the short MA has stayed on the new
+// side of the long MA for the configured number of confirmation bars.
+func (mas *MovingAverageStrategy) detectCross(shortMA, longMA float64) CrossEvent {
+	relation := 0
+	if shortMA > longMA {
+		relation = 1
+	} else if shortMA < longMA {
+		relation = -1
+	}
+
+	if relation == 0 {
+		return CrossNone
+	}
+
+	if mas.relation != 0 && relation != mas.relation {
+		if mas.pending != CrossNone {
+			// The previous cross reversed before it was confirmed.
+			mas.pending = CrossNone
+		} else if relation > 0 {
+			mas.pending = CrossGolden
+		} else {
+			mas.pending = CrossDeath
+		}
+		mas.pendingBars = 0
+	}
+	mas.relation = relation
+
+	if mas.pending == CrossNone {
+		return CrossNone
+	}
+
+	if mas.pendingBars < mas.config.ConfirmationBars {
+		mas.pendingBars++
+		return CrossNone
+	}
+
+	event := mas.pending
+	mas.pending = CrossNone
+	mas.lastCross = event
+	return event
+}
+
+func (mas *MovingAverageStrategy) crossReason(event CrossEvent, shortMA, longMA float64) string {
+	direction := "above"
+	if event == CrossDeath {
+		direction = "below"
+	}
+
+	maType := strings.ToUpper(string(mas.config.Type))
+	return fmt.Sprintf("%s: %s(%d) %.2f crossed %s %s(%d) %.2f",
+		strings.ToLower(string(event)), maType, mas.config.ShortPeriod, shortMA, direction, maType, mas.config.LongPeriod, longMA)
+}
+
 func (mas *MovingAverageStrategy) calculateMA(period int) float64 {
 	if len(mas.priceHistory) < period {
 		return 0
 	}
 
-	sum := 0.0
-	start := len(mas.priceHistory) - period
-	for i := start; i < len(mas.priceHistory); i++ {
-		sum += mas.priceHistory[i]
+	if mas.config.Type == MATypeEMA {
+		return indicator.EMA(mas.priceHistory, period)
+	}
+	return indicator.SMA(mas.priceHistory, period)
+}
+
+type movingAverageState struct {
+	PriceHistory []float64  `json:"price_history"`
+	Relation     int        `json:"relation"`
+	Pending      CrossEvent `json:"pending"`
+	PendingBars  int        `json:"pending_bars"`
+	LastCross    CrossEvent `json:"last_cross"`
+	InPosition   bool       `json:"in_position"`
+}
+
+// WarmUp replays historical closes to establish which side of the long MA
+// the short MA is on. Crosses seen during warm-up are not signaled.
+func (mas *MovingAverageStrategy) WarmUp(candles []market.Candle) {
+	for _, candle := range candles {
+		mas.priceHistory = trimHistory(append(mas.priceHistory, candle.Close), mas.maxHistory)
+		if len(mas.priceHistory) >= mas.config.LongPeriod {
+			mas.detectCross(mas.calculateMA(mas.config.ShortPeriod), mas.calculateMA(mas.config.LongPeriod))
+		}
+	}
+	mas.pending = CrossNone
+	mas.pendingBars = 0
+}
+
+func (mas *MovingAverageStrategy) SnapshotState() ([]byte, error) {
+	return json.Marshal(movingAverageState{
+		PriceHistory: mas.priceHistory,
+		Relation:     mas.relation,
+		Pending:      mas.pending,
+		PendingBars:  mas.pendingBars,
+		LastCross:    mas.lastCross,
+		InPosition:   mas.inPosition,
+	})
+}
+
+func (mas *MovingAverageStrategy) RestoreState(data []byte) error {
+	var state movingAverageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
 	}
 
-	return sum / float64(period)
+	mas.priceHistory = trimHistory(state.PriceHistory, mas.maxHistory)
+	mas.relation = state.Relation
+	mas.pending = state.Pending
+	mas.pendingBars = state.PendingBars
+	mas.lastCross = state.LastCross
+	mas.inPosition = state.InPosition
+	return nil
 }
@@ -0,0 +1,59 @@
+package strategy
+
+import (
+	"maps"
+	"testing"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/market"
+)
+
+// analyzeAll feeds prices to s one tick at a time and returns its signals.
+func analyzeAll(s Strategy, prices []float64) []Signal {
+	signals := make([]Signal, len(prices))
+	for i, price := range prices {
+		signals[i] = s.Analyze(&market.Data{Symbol: "BTCUSDT", Price: decimal.MustFromFloat(price)})
+	}
+	return signals
+}
+
+func TestMovingAverageCrossover(t *testing.T) {
+	// The 2-bar SMA falls below the 4-bar SMA at bar 3 and crosses back
+	// above it at bar 5.
+	rally := []float64{10, 9, 8, 7, 6, 10, 11, 12, 13, 14}
+	// The same golden cross reverses at bar 7, before two bars confirm it.
+	whipsaw := []float64{10, 9, 8, 7, 6, 10, 11, 2, 1, 0.5}
+
+	tests := []struct {
+		name         string
+		prices       []float64
+		confirmation int
+		// want maps the bars that signal to their action.
+		want map[int]Action
+	}{
+		{name: "golden cross", prices: rally, want: map[int]Action{5: ActionBuy}},
+		{name: "golden cross confirmed after two bars", prices: rally, confirmation: 2, want: map[int]Action{7: ActionBuy}},
+		{name: "death cross after a golden cross", prices: whipsaw, want: map[int]Action{5: ActionBuy, 7: ActionSell}},
+		{name: "cross reversed before confirmation", prices: whipsaw, confirmation: 2, want: map[int]Action{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mas := NewMovingAverageStrategyWithConfig(MovingAverageConfig{
+				ShortPeriod:      2,
+				LongPeriod:       4,
+				ConfirmationBars: tt.confirmation,
+			})
+
+			got := make(map[int]Action)
+			for i, signal := range analyzeAll(mas, tt.prices) {
+				if signal.Action != ActionHold {
+					got[i] = signal.Action
+				}
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("signals = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
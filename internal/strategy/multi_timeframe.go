@@ -0,0 +1,171 @@
+package strategy
+
+import (
+	"fmt"
+
+	"trading-bot/internal/indicator"
+	"trading-bot/internal/market"
+)
+
+type MultiTimeframeConfig struct {
+	EntryInterval string
+	TrendInterval string
+	FastPeriod    int
+	SlowPeriod    int
+	TrendPeriod   int
+	BuyAmount     float64
+	SellAmount    float64
+}
+
+// MultiTimeframeStrategy enters on a fast/slow EMA cross of the entry
+// timeframe, but only buys while the last closed trend bar is above its EMA.
+type MultiTimeframeStrategy struct {
+	config     MultiTimeframeConfig
+	bars       *market.Aggregator
+	lastEntry  int64
+	relation   int
+	inPosition bool
+}
+
+func NewMultiTimeframeStrategy(config MultiTimeframeConfig) (*MultiTimeframeStrategy, error) {
+	if config.EntryInterval == "" {
+		config.EntryInterval = "1m"
+	}
+	if config.TrendInterval == "" {
+		config.TrendInterval = "1h"
+	}
+	if config.FastPeriod <= 0 {
+		config.FastPeriod = 9
+	}
+	if config.SlowPeriod <= 0 {
+		config.SlowPeriod = 21
+	}
+	if config.TrendPeriod <= 0 {
+		config.TrendPeriod = 50
+	}
+	if config.BuyAmount <= 0 {
+		config.BuyAmount = 1000.0
+	}
+	if config.SellAmount <= 0 {
+		config.SellAmount = 0.5
+	}
+
+	bars, err := market.NewAggregator([]string{config.EntryInterval, config.TrendInterval}, max(config.SlowPeriod, config.TrendPeriod)*4)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MultiTimeframeStrategy{
+		config: config,
+		bars:   bars,
+	}, nil
+}
+
+func (mtf *MultiTimeframeStrategy) Name() string {
+	return "MultiTimeframe"
+}
+
+func (mtf *MultiTimeframeStrategy) Timeframes() []string {
+	return []string{mtf.config.EntryInterval, mtf.config.TrendInterval}
+}
+
+func (mtf *MultiTimeframeStrategy) SetInPosition(inPosition bool) {
+	mtf.inPosition = inPosition
+}
+
+// Analyze builds its own bars from ticks when the strategy is used without an
+// AnalysisContext.
+func (mtf *MultiTimeframeStrategy) Analyze(data *market.Data) Signal {
+	mtf.bars.AddTick(data)
+	return mtf.evaluate(data, mtf.bars.Snapshot())
+}
+
+func (mtf *MultiTimeframeStrategy) AnalyzeContext(ctx *AnalysisContext) Signal {
+	mtf.inPosition = ctx.InPosition()
+	if ctx.Bars == nil {
+		return mtf.Analyze(ctx.Data)
+	}
+	return mtf.evaluate(ctx.Data, ctx.Bars)
+}
+
+func (mtf *MultiTimeframeStrategy) evaluate(data *market.Data, bars map[string][]market.Candle) Signal {
+	hold := Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
+
+	entryBars := bars[mtf.config.EntryInterval]
+	trendBars := bars[mtf.config.TrendInterval]
+	if len(entryBars) < mtf.config.SlowPeriod || len(trendBars) < mtf.config.TrendPeriod {
+		return hold
+	}
+
+	// Only re-evaluate when a new entry bar has closed.
+	latest := entryBars[len(entryBars)-1]
+	if latest.CloseTime.UnixMilli() == mtf.lastEntry {
+		return hold
+	}
+	mtf.lastEntry = latest.CloseTime.UnixMilli()
+
+	entryCloses := closes(entryBars)
+	fast := indicator.EMA(entryCloses, mtf.config.FastPeriod)
+	slow := indicator.EMA(entryCloses, mtf.config.SlowPeriod)
+
+	trendCloses := closes(trendBars)
+	trendEMA := indicator.EMA(trendCloses, mtf.config.TrendPeriod)
+	trendClose := trendCloses[len(trendCloses)-1]
+
+	relation := 0
+	if fast > slow {
+		relation = 1
+	} else if fast < slow {
+		relation = -1
+	}
+
+	previous := mtf.relation
+	if relation != 0 {
+		mtf.relation = relation
+	}
+	if previous == 0 || relation == 0 || relation == previous {
+		return hold
+	}
+
+	indicators := map[string]float64{
+		"entry_fast_ema": fast,
+		"entry_slow_ema": slow,
+		"trend_close":    trendClose,
+		"trend_ema":      trendEMA,
+	}
+
+	if relation > 0 && trendClose > trendEMA && !mtf.inPosition {
+		mtf.inPosition = true
+		return Signal{
+			Action:     ActionBuy,
+			Symbol:     "BTC",
+			Amount:     mtf.config.BuyAmount,
+			Confidence: clampConfidence(0.5 + (trendClose-trendEMA)/trendEMA*50),
+			Reason: fmt.Sprintf("%s EMA(%d) crossed above EMA(%d) with %s close %.2f above EMA(%d) %.2f",
+				mtf.config.EntryInterval, mtf.config.FastPeriod, mtf.config.SlowPeriod, mtf.config.TrendInterval, trendClose, mtf.config.TrendPeriod, trendEMA),
+			Indicators: indicators,
+		}
+	}
+
+	if relation < 0 && mtf.inPosition {
+		mtf.inPosition = false
+		return Signal{
+			Action:     ActionSell,
+			Symbol:     "BTC",
+			Amount:     mtf.config.SellAmount,
+			Confidence: 0.5,
+			Reason:     fmt.Sprintf("%s EMA(%d) crossed below EMA(%d)", mtf.config.EntryInterval, mtf.config.FastPeriod, mtf.config.SlowPeriod),
+			Indicators: indicators,
+		}
+	}
+
+	return hold
+}
+
+func closes(candles []market.Candle) []float64 {
+	values := make([]float64, len(candles))
+	for i, candle := range candles {
+		values[i] = candle.Close
+	}
+	return values
+}
@@ -1,7 +1,10 @@
 package strategy
 
 import (
-	"math"
+	"encoding/json"
+	"fmt"
+
+	"trading-bot/internal/indicator"
 	"trading-bot/internal/market"
 )
 
@@ -28,7 +31,7 @@ func (rsi *RSIStrategy) Name() string {
 }
 
 func (rsi *RSIStrategy) Analyze(data *market.Data) Signal {
-	rsi.priceHistory = append(rsi.priceHistory, data.Price)
+	rsi.priceHistory = append(rsi.priceHistory, data.Price.Float64())
 
 	if len(rsi.priceHistory) > rsi.maxHistory {
 		rsi.priceHistory = rsi.priceHistory[1:]
@@ -39,18 +42,26 @@ func (rsi *RSIStrategy) Analyze(data *market.Data) Signal {
 	}
 
 	rsiValue := rsi.calculateRSI()
+	indicators := map[string]float64{"rsi": rsiValue}
 
+	// Confidence grows from 0.5 at the threshold to 1 at the extreme.
 	if rsiValue < rsi.oversold {
 		return Signal{
-			Action: ActionBuy,
-			Symbol: "BTC",
-			Amount: 500.0,
+			Action:     ActionBuy,
+			Symbol:     "BTC",
+			Amount:     500.0,
+			Confidence: clampConfidence(0.5 + 0.5*(rsi.oversold-rsiValue)/rsi.oversold),
+			Reason:     fmt.Sprintf("RSI(%d) %.2f below oversold %.0f", rsi.period, rsiValue, rsi.oversold),
+			Indicators: indicators,
 		}
 	} else if rsiValue > rsi.overbought {
 		return Signal{
-			Action: ActionSell,
-			Symbol: "BTC",
-			Amount: 0.3,
+			Action:     ActionSell,
+			Symbol:     "BTC",
+			Amount:     0.3,
+			Confidence: clampConfidence(0.5 + 0.5*(rsiValue-rsi.overbought)/(100-rsi.overbought)),
+			Reason:     fmt.Sprintf("RSI(%d) %.2f above overbought %.0f", rsi.period, rsiValue, rsi.overbought),
+			Indicators: indicators,
 		}
 	}
 
@@ -58,40 +69,29 @@ func (rsi *RSIStrategy) Analyze(data *market.Data) Signal {
 }
 
 func (rsi *RSIStrategy) calculateRSI() float64 {
-	gains := make([]float64, 0)
-	losses := make([]float64, 0)
-
-	for i := 1; i < len(rsi.priceHistory); i++ {
-		change := rsi.priceHistory[i] - rsi.priceHistory[i-1]
-		if change > 0 {
-			gains = append(gains, change)
-			losses = append(losses, 0)
-		} else {
-			gains = append(gains, 0)
-			losses = append(losses, math.Abs(change))
-		}
-	}
-
-	if len(gains) < rsi.period {
-		return 50.0
-	}
+	return indicator.RSI(rsi.priceHistory, rsi.period)
+}
 
-	avgGain := 0.0
-	avgLoss := 0.0
+type rsiState struct {
+	PriceHistory []float64 `json:"price_history"`
+}
 
-	start := len(gains) - rsi.period
-	for i := start; i < len(gains); i++ {
-		avgGain += gains[i]
-		avgLoss += losses[i]
+func (rsi *RSIStrategy) WarmUp(candles []market.Candle) {
+	for _, candle := range candles {
+		rsi.priceHistory = trimHistory(append(rsi.priceHistory, candle.Close), rsi.maxHistory)
 	}
+}
 
-	avgGain /= float64(rsi.period)
-	avgLoss /= float64(rsi.period)
+func (rsi *RSIStrategy) SnapshotState() ([]byte, error) {
+	return json.Marshal(rsiState{PriceHistory: rsi.priceHistory})
+}
 
-	if avgLoss == 0 {
-		return 100.0
+func (rsi *RSIStrategy) RestoreState(data []byte) error {
+	var state rsiState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
 	}
 
-	rs := avgGain / avgLoss
-	return 100.0 - (100.0 / (1.0 + rs))
+	rsi.priceHistory = trimHistory(state.PriceHistory, rsi.maxHistory)
+	return nil
 }
@@ -0,0 +1,170 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"trading-bot/internal/market"
+	"trading-bot/internal/script"
+)
+
+type ScriptConfig struct {
+	Entry      string
+	Exit       string
+	Interval   time.Duration
+	BuyAmount  float64
+	SellAmount float64
+}
+
+// ScriptedStrategy buys when its entry rule holds while flat and sells when
+// its exit rule holds while long. With an Interval the rules are evaluated on
+// closed candles, otherwise every tick is treated as a bar.
+type ScriptedStrategy struct {
+	config     ScriptConfig
+	entry      *script.Program
+	exit       *script.Program
+	candles    *market.CandleBuilder
+	series     script.Series
+	maxHistory int
+	inPosition bool
+}
+
+func NewScriptedStrategy(config ScriptConfig) (*ScriptedStrategy, error) {
+	entry, err := script.Compile(config.Entry)
+	if err != nil {
+		return nil, fmt.Errorf("entry rule: %w", err)
+	}
+	exit, err := script.Compile(config.Exit)
+	if err != nil {
+		return nil, fmt.Errorf("exit rule: %w", err)
+	}
+
+	if config.BuyAmount <= 0 {
+		config.BuyAmount = 1000.0
+	}
+	if config.SellAmount <= 0 {
+		config.SellAmount = 0.5
+	}
+
+	lookback := max(entry.Lookback(), exit.Lookback())
+
+	ss := &ScriptedStrategy{
+		config:     config,
+		entry:      entry,
+		exit:       exit,
+		maxHistory: emaHistory(lookback) + 10,
+	}
+	if config.Interval > 0 {
+		ss.candles = market.NewCandleBuilder(config.Interval)
+	}
+
+	return ss, nil
+}
+
+func (ss *ScriptedStrategy) Name() string {
+	return "Scripted"
+}
+
+func (ss *ScriptedStrategy) CandleInterval() time.Duration {
+	return ss.config.Interval
+}
+
+func (ss *ScriptedStrategy) SetInPosition(inPosition bool) {
+	ss.inPosition = inPosition
+}
+
+func (ss *ScriptedStrategy) Analyze(data *market.Data) Signal {
+	if ss.candles == nil {
+		price := data.Price.Float64()
+		return ss.AnalyzeCandle(&market.Candle{
+			Symbol:    data.Symbol,
+			OpenTime:  data.Timestamp,
+			CloseTime: data.Timestamp,
+			Open:      price,
+			High:      price,
+			Low:       price,
+			Close:     price,
+			Volume:    data.Volume,
+		})
+	}
+
+	candle, closed := ss.candles.Add(data)
+	if !closed {
+		return Signal{Action: ActionHold, Symbol: data.Symbol, Amount: 0}
+	}
+	return ss.AnalyzeCandle(candle)
+}
+
+func (ss *ScriptedStrategy) AnalyzeCandle(candle *market.Candle) Signal {
+	ss.addCandle(candle)
+	hold := Signal{Action: ActionHold, Symbol: candle.Symbol, Amount: 0}
+
+	rule, action, amount := ss.entry, ActionBuy, ss.config.BuyAmount
+	if ss.inPosition {
+		rule, action, amount = ss.exit, ActionSell, ss.config.SellAmount
+	}
+
+	matched, err := rule.Eval(&ss.series)
+	if err != nil || !matched {
+		return hold
+	}
+
+	ss.inPosition = action == ActionBuy
+	return Signal{
+		Action:     action,
+		Symbol:     "BTC",
+		Amount:     amount,
+		Confidence: 0.5,
+		Reason:     fmt.Sprintf("rule matched: %s", rule),
+		Indicators: rule.Values(&ss.series),
+	}
+}
+
+func (ss *ScriptedStrategy) addCandle(candle *market.Candle) {
+	ss.series.Open = trimHistory(append(ss.series.Open, candle.Open), ss.maxHistory)
+	ss.series.High = trimHistory(append(ss.series.High, candle.High), ss.maxHistory)
+	ss.series.Low = trimHistory(append(ss.series.Low, candle.Low), ss.maxHistory)
+	ss.series.Close = trimHistory(append(ss.series.Close, candle.Close), ss.maxHistory)
+	ss.series.Volume = trimHistory(append(ss.series.Volume, candle.Volume), ss.maxHistory)
+}
+
+type scriptedState struct {
+	Series     script.Series `json:"series"`
+	InPosition bool          `json:"in_position"`
+}
+
+func (ss *ScriptedStrategy) WarmUp(candles []market.Candle) {
+	for i := range candles {
+		ss.addCandle(&candles[i])
+	}
+}
+
+func (ss *ScriptedStrategy) SnapshotState() ([]byte, error) {
+	return json.Marshal(scriptedState{
+		Series:     ss.series,
+		InPosition: ss.inPosition,
+	})
+}
+
+func (ss *ScriptedStrategy) RestoreState(data []byte) error {
+	var state scriptedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+
+	n := len(state.Series.Close)
+	if len(state.Series.Open) != n || len(state.Series.High) != n || len(state.Series.Low) != n || len(state.Series.Volume) != n {
+		return fmt.Errorf("series lengths differ")
+	}
+
+	ss.series = script.Series{
+		Open:   trimHistory(state.Series.Open, ss.maxHistory),
+		High:   trimHistory(state.Series.High, ss.maxHistory),
+		Low:    trimHistory(state.Series.Low, ss.maxHistory),
+		Close:  trimHistory(state.Series.Close, ss.maxHistory),
+		Volume: trimHistory(state.Series.Volume, ss.maxHistory),
+	}
+	ss.inPosition = state.InPosition
+	return nil
+}
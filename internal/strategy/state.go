@@ -0,0 +1,136 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"trading-bot/internal/decimal"
+	"trading-bot/internal/market"
+)
+
+type WarmStarter interface {
+	WarmUp(candles []market.Candle)
+}
+
+type Stateful interface {
+	SnapshotState() ([]byte, error)
+	RestoreState(data []byte) error
+}
+
+type stateFile struct {
+	Strategy string          `json:"strategy"`
+	SavedAt  time.Time       `json:"saved_at"`
+	State    json.RawMessage `json:"state"`
+}
+
+// WarmUp primes a strategy with historical candles. Strategies that do not
+// implement WarmStarter are fed the candle closes through Analyze and the
+// resulting signals are discarded.
+func WarmUp(s Strategy, candles []market.Candle) {
+	if starter, ok := s.(WarmStarter); ok {
+		starter.WarmUp(candles)
+		return
+	}
+
+	for i := range candles {
+		// A close out of Decimal range is no price; skip the candle.
+		if data, err := candleData(&candles[i]); err == nil {
+			s.Analyze(data)
+		}
+	}
+}
+
+func SaveState(s Strategy, filename string) error {
+	stateful, ok := s.(Stateful)
+	if !ok {
+		return fmt.Errorf("strategy %s does not support state snapshots", s.Name())
+	}
+
+	state, err := stateful.SnapshotState()
+	if err != nil {
+		return fmt.Errorf("error snapshotting %s state: %w", s.Name(), err)
+	}
+
+	data, err := json.MarshalIndent(stateFile{
+		Strategy: s.Name(),
+		SavedAt:  time.Now(),
+		State:    state,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling strategy state: %w", err)
+	}
+
+	if dir := filepath.Dir(filename); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creating state directory: %w", err)
+		}
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated
+	// snapshot behind.
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing strategy state: %w", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		return fmt.Errorf("error writing strategy state: %w", err)
+	}
+
+	return nil
+}
+
+// LoadState restores a snapshot written by SaveState and returns the time it
+// was saved. Snapshots older than maxAge are refused without touching the
+// strategy; a zero maxAge accepts any age.
+func LoadState(s Strategy, filename string, maxAge time.Duration) (time.Time, error) {
+	stateful, ok := s.(Stateful)
+	if !ok {
+		return time.Time{}, fmt.Errorf("strategy %s does not support state snapshots", s.Name())
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error reading strategy state: %w", err)
+	}
+
+	var file stateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return time.Time{}, fmt.Errorf("error parsing strategy state: %w", err)
+	}
+
+	if file.Strategy != s.Name() {
+		return time.Time{}, fmt.Errorf("state file belongs to strategy %s, not %s", file.Strategy, s.Name())
+	}
+	if age := time.Since(file.SavedAt); maxAge > 0 && age > maxAge {
+		return time.Time{}, fmt.Errorf("state saved %s ago is older than the maximum age of %s", age.Round(time.Second), maxAge)
+	}
+
+	if err := stateful.RestoreState(file.State); err != nil {
+		return time.Time{}, fmt.Errorf("error restoring %s state: %w", s.Name(), err)
+	}
+
+	return file.SavedAt, nil
+}
+
+func candleData(candle *market.Candle) (*market.Data, error) {
+	price, err := decimal.FromFloat(candle.Close)
+	if err != nil {
+		return nil, err
+	}
+	return &market.Data{
+		Symbol:    candle.Symbol,
+		Price:     price,
+		Volume:    candle.Volume,
+		Timestamp: candle.CloseTime,
+	}, nil
+}
+
+func trimHistory(history []float64, maxHistory int) []float64 {
+	if len(history) > maxHistory {
+		return history[len(history)-maxHistory:]
+	}
+	return history
+}
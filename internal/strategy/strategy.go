@@ -1,6 +1,8 @@
 package strategy
 
 import (
+	"time"
+
 	"trading-bot/internal/market"
 )
 
@@ -12,13 +14,65 @@ const (
 	ActionHold Action = "HOLD"
 )
 
+type OrderType string
+
+const (
+	OrderMarket OrderType = "MARKET"
+	OrderLimit  OrderType = "LIMIT"
+)
+
+// Signal is a strategy's trading decision. Everything after Amount is
+// optional metadata explaining the decision; the order fields are hints the
+// bot may use when placing the order.
 type Signal struct {
 	Action Action
 	Symbol string
 	Amount float64
+
+	Confidence float64
+	Reason     string
+	Indicators map[string]float64
+	Sources    []string
+
+	OrderType  OrderType
+	LimitPrice float64
+	StopPrice  float64
+}
+
+func clampConfidence(confidence float64) float64 {
+	return max(0, min(1, confidence))
+}
+
+// emaHistory is how many prices an EMA over period needs. EMAs are seeded
+// from the start of the window, so keep enough history for the seed to have
+// decayed away.
+func emaHistory(period int) int {
+	return period * 4
 }
 
 type Strategy interface {
 	Analyze(data *market.Data) Signal
 	Name() string
 }
+
+type PositionTracker interface {
+	SetInPosition(inPosition bool)
+}
+
+// CandleStrategy is a strategy that decides on closed candles of its own
+// interval. An interval of zero means it accepts candles of any interval.
+type CandleStrategy interface {
+	Strategy
+	AnalyzeCandle(candle *market.Candle) Signal
+	CandleInterval() time.Duration
+}
+
+// VolumeStrategy is a strategy whose signals depend on candle volume, which
+// candles built from polled price ticks do not carry.
+type VolumeStrategy interface {
+	NeedsVolume() bool
+}
+
+type TrendReporter interface {
+	Trend() Action
+}
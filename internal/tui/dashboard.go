@@ -0,0 +1,146 @@
+// Package tui renders a live terminal dashboard of a running bot using ANSI
+// escape sequences.
+package tui
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"trading-bot/internal/api"
+	"trading-bot/internal/events"
+)
+
+// Source is the bot the dashboard watches.
+type Source interface {
+	Status(ctx context.Context) (api.Status, error)
+	Events() *events.Bus
+}
+
+type Options struct {
+	// Refresh is how often the screen is redrawn and the status polled.
+	Refresh time.Duration
+	// Width is the number of columns drawn.
+	Width int
+	// Color enables ANSI colours.
+	Color bool
+}
+
+const (
+	maxPrices = 500
+	maxFills  = 8
+)
+
+// Dashboard draws the bot's status, polled once per refresh, together with
+// what it has seen on the event bus: recent prices for the sparkline, risk
+// limit hits and errors.
+type Dashboard struct {
+	source  Source
+	out     io.Writer
+	options Options
+	sub     *events.Subscription
+
+	status    api.Status
+	statusErr error
+	polled    bool
+	stopping  bool
+
+	prices      []float64
+	limitHits   int
+	lastLimit   *events.RiskLimitHit
+	lastLimitAt time.Time
+	errors      int
+	lastError   *events.Error
+	lastErrorAt time.Time
+}
+
+// New subscribes to the source's events, so it should be called before the
+// bot starts to see every tick.
+func New(source Source, out io.Writer, options Options) *Dashboard {
+	if options.Refresh <= 0 {
+		options.Refresh = time.Second
+	}
+	options.Width = max(options.Width, 60)
+
+	return &Dashboard{
+		source:  source,
+		out:     out,
+		options: options,
+		sub: source.Events().Subscribe(1024,
+			events.KindTickReceived, events.KindRiskLimitHit, events.KindError, events.KindStopped),
+	}
+}
+
+// Run takes over the terminal and redraws until the bot's event bus closes,
+// then restores it. Once ctx is done the dashboard shows the bot stopping
+// but keeps drawing until shutdown completes.
+func (d *Dashboard) Run(ctx context.Context) error {
+	if _, err := io.WriteString(d.out, enterScreen); err != nil {
+		return err
+	}
+	defer io.WriteString(d.out, leaveScreen)
+
+	ticker := time.NewTicker(d.options.Refresh)
+	defer ticker.Stop()
+
+	done := ctx.Done()
+	d.poll(ctx)
+	if err := d.draw(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case envelope, ok := <-d.sub.C():
+			if !ok {
+				return nil
+			}
+			d.observe(envelope)
+		case <-done:
+			d.stopping, done = true, nil
+		case <-ticker.C:
+			if !d.stopping {
+				d.poll(ctx)
+			}
+			if err := d.draw(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (d *Dashboard) observe(envelope events.Envelope) {
+	switch e := envelope.Event.(type) {
+	case events.TickReceived:
+		d.prices = append(d.prices, e.Data.Price.Float64())
+		if len(d.prices) > maxPrices {
+			d.prices = d.prices[len(d.prices)-maxPrices:]
+		}
+	case events.RiskLimitHit:
+		d.limitHits++
+		d.lastLimit, d.lastLimitAt = &e, envelope.Time
+	case events.Error:
+		d.errors++
+		d.lastError, d.lastErrorAt = &e, envelope.Time
+	case events.Stopped:
+		d.stopping = true
+	}
+}
+
+// poll fetches the status, giving up after one refresh interval so a slow
+// tick does not freeze the screen.
+func (d *Dashboard) poll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, d.options.Refresh)
+	defer cancel()
+
+	status, err := d.source.Status(ctx)
+	d.statusErr = err
+	if err == nil {
+		d.status, d.polled = status, true
+	}
+}
+
+func (d *Dashboard) draw() error {
+	_, err := io.WriteString(d.out, d.render(time.Now()))
+	return err
+}
@@ -0,0 +1,357 @@
+package tui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"trading-bot/internal/api"
+	"trading-bot/internal/portfolio"
+)
+
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	home        = "\x1b[H"
+	clearLine   = "\x1b[K"
+	clearBelow  = "\x1b[J"
+
+	bold   = "\x1b[1m"
+	dim    = "\x1b[2m"
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	cyan   = "\x1b[36m"
+	reset  = "\x1b[0m"
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// frame accumulates one screen, clearing the rest of each line so shorter
+// redraws leave nothing behind.
+type frame struct {
+	b     strings.Builder
+	color bool
+	width int
+}
+
+func (f *frame) line(format string, args ...any) {
+	fmt.Fprintf(&f.b, format, args...)
+	f.b.WriteString(clearLine + "\n")
+}
+
+func (f *frame) rule() {
+	f.line("%s", f.paint(dim, strings.Repeat("─", f.width)))
+}
+
+func (f *frame) heading(title string) {
+	f.line("")
+	f.line("%s", f.paint(bold+cyan, title))
+}
+
+// paint wraps s in an ANSI style when colours are on. Pad before painting,
+// since escape codes throw off fmt widths.
+func (f *frame) paint(style, s string) string {
+	if !f.color || style == "" {
+		return s
+	}
+	return style + s + reset
+}
+
+// signed paints a value green when positive and red when negative.
+func (f *frame) signed(v float64, s string) string {
+	switch {
+	case v > 0:
+		return f.paint(green, s)
+	case v < 0:
+		return f.paint(red, s)
+	}
+	return s
+}
+
+func (f *frame) side(side, s string) string {
+	switch side {
+	case "BUY":
+		return f.paint(green, s)
+	case "SELL":
+		return f.paint(red, s)
+	}
+	return f.paint(yellow, s)
+}
+
+func (d *Dashboard) render(now time.Time) string {
+	f := &frame{color: d.options.Color, width: d.options.Width}
+	f.b.WriteString(home)
+	s := d.status
+
+	d.renderHeader(f, now)
+	f.rule()
+
+	if !d.polled {
+		if d.statusErr != nil {
+			f.line(" Waiting for the bot: %v", d.statusErr)
+		} else {
+			f.line(" Waiting for the bot...")
+		}
+	} else {
+		d.renderPrice(f, now)
+		d.renderSignal(f)
+		renderPositions(f, s)
+		renderOrders(f, s, now)
+		renderFills(f, s)
+		d.renderRisk(f)
+	}
+
+	f.line("")
+	f.rule()
+	footer := " Ctrl+C to stop"
+	if d.stopping {
+		footer = " Shutting down..."
+	}
+	f.line("%s", f.paint(dim, footer))
+	f.b.WriteString(clearBelow)
+	return f.b.String()
+}
+
+func (d *Dashboard) renderHeader(f *frame, now time.Time) {
+	s := d.status
+	if !d.polled {
+		f.line(" %s%s", f.paint(bold, "Trading Bot"), pad(now.Format(time.TimeOnly), f.width-len(" Trading Bot")))
+		return
+	}
+
+	mode := f.paint(bold+yellow, "DRY RUN")
+	if !s.DryRun {
+		mode = f.paint(bold+red, "LIVE")
+	}
+	left := fmt.Sprintf(" %s  ·  %s  ·  %s  ·  %s",
+		f.paint(bold, s.Symbol), s.Strategy, mode, d.state(f))
+	plain := fmt.Sprintf(" %s  ·  %s  ·  %s  ·  %s", s.Symbol, s.Strategy, modeName(s.DryRun), d.stateName())
+	f.line("%s%s", left, pad(now.Format(time.TimeOnly), f.width-utf8.RuneCountInString(plain)))
+}
+
+func (d *Dashboard) stateName() string {
+	switch {
+	case d.status.Halted:
+		return "HALTED"
+	case d.status.Paused:
+		return "PAUSED"
+	}
+	return "ACTIVE"
+}
+
+func (d *Dashboard) state(f *frame) string {
+	name := d.stateName()
+	switch name {
+	case "HALTED":
+		return f.paint(bold+red, name)
+	case "PAUSED":
+		return f.paint(bold+yellow, name)
+	}
+	return f.paint(bold+green, name)
+}
+
+func modeName(dryRun bool) string {
+	if dryRun {
+		return "DRY RUN"
+	}
+	return "LIVE"
+}
+
+func (d *Dashboard) renderPrice(f *frame, now time.Time) {
+	s := d.status
+	if s.LastTick.IsZero() {
+		f.line(" Price    waiting for the first tick")
+		return
+	}
+
+	change := ""
+	if len(d.prices) > 1 && d.prices[0] != 0 {
+		pct := (d.prices[len(d.prices)-1] - d.prices[0]) / d.prices[0] * 100
+		change = f.signed(pct, fmt.Sprintf("%+.2f%%", pct))
+	}
+	f.line(" Price    %s  %s   %s", f.paint(bold, s.LastPrice.String()), change,
+		f.paint(dim, fmt.Sprintf("last tick %s ago", age(now, s.LastTick))))
+
+	width := f.width - 10
+	prices := d.prices[max(0, len(d.prices)-width):]
+	if len(prices) == 0 {
+		return
+	}
+	low, high := slices.Min(prices), slices.Max(prices)
+	f.line("          %s", f.paint(cyan, sparkline(prices, low, high)))
+	places := portfolio.Precision(s.CashAsset)
+	f.line("          %s", f.paint(dim, fmt.Sprintf("low %.*f  high %.*f  over %d ticks", places, low, places, high, len(prices))))
+}
+
+// sparkline draws one bar per price, scaled between low and high.
+func sparkline(prices []float64, low, high float64) string {
+	var b strings.Builder
+	for _, p := range prices {
+		i := len(sparks) / 2
+		if high > low {
+			i = int((p - low) / (high - low) * float64(len(sparks)-1))
+		}
+		b.WriteRune(sparks[i])
+	}
+	return b.String()
+}
+
+func (d *Dashboard) renderSignal(f *frame) {
+	f.heading("Signal")
+	signal := d.status.Signal
+	if signal == nil {
+		f.line(" none yet")
+		return
+	}
+
+	line := fmt.Sprintf(" %s  confidence %.2f", f.side(signal.Action, pad(signal.Action, -4)), signal.Confidence)
+	if signal.Reason != "" {
+		line += "  " + signal.Reason
+	}
+	f.line("%s", line)
+
+	names := slices.Sorted(maps.Keys(signal.Indicators))
+	var cells []string
+	for _, name := range names {
+		cells = append(cells, fmt.Sprintf("%s %s", f.paint(dim, name), formatIndicator(signal.Indicators[name])))
+	}
+	for len(cells) > 0 {
+		n := min(len(cells), 4)
+		f.line(" %s", strings.Join(cells[:n], "   "))
+		cells = cells[n:]
+	}
+}
+
+func formatIndicator(v float64) string {
+	if v != 0 && (v < 0.01 && v > -0.01) {
+		return fmt.Sprintf("%.6f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func renderPositions(f *frame, s api.Status) {
+	f.heading("Positions")
+	if len(s.Positions) == 0 {
+		f.line(" no open positions")
+	} else {
+		f.line("%s", f.paint(dim, fmt.Sprintf(" %-8s %16s %14s %14s %14s %14s", "ASSET", "QUANTITY", "AVG COST", "PRICE", "VALUE", "UNREALIZED")))
+		reporting := portfolio.Precision(s.ReportingCurrency)
+		for _, p := range s.Positions {
+			f.line(" %-8s %16s %14s %14.*f %14.*f %s",
+				p.Asset, p.Quantity, p.AverageCost.StringFixed(portfolio.Precision(p.Quote)), reporting, p.Price, reporting, p.MarketValue,
+				f.signed(p.UnrealizedPnL, fmt.Sprintf("%+14.*f", reporting, p.UnrealizedPnL)))
+		}
+	}
+
+	realized := s.RealizedPnL.Float64()
+	reporting := portfolio.Precision(s.ReportingCurrency)
+	f.line(" Equity %s %s   Realized %s   Unrealized %s   Drawdown %s",
+		f.paint(bold, fmt.Sprintf("%.*f", reporting, s.Equity)), s.ReportingCurrency,
+		f.signed(realized, fmt.Sprintf("%+.*f %s", portfolio.Precision(s.CashAsset), realized, s.CashAsset)),
+		f.signed(s.UnrealizedPnL, fmt.Sprintf("%+.*f", reporting, s.UnrealizedPnL)),
+		f.signed(-s.Drawdown, fmt.Sprintf("%.2f%%", s.Drawdown*100)))
+}
+
+func renderOrders(f *frame, s api.Status, now time.Time) {
+	f.heading("Open Orders")
+	if len(s.OpenOrders) == 0 {
+		f.line(" none")
+		return
+	}
+	f.line("%s", f.paint(dim, fmt.Sprintf(" %-12s %-5s %-7s %14s %14s %14s %-16s %s", "ID", "SIDE", "TYPE", "QUANTITY", "PRICE", "FILLED", "STATUS", "AGE")))
+	for _, o := range s.OpenOrders {
+		f.line(" %-12d %s %-7s %14s %14s %14s %-16s %s",
+			o.ID, f.side(o.Side, pad(o.Side, -5)), o.Type, o.Quantity, o.Price, o.ExecutedQty, o.Status, age(now, o.CreatedAt))
+	}
+}
+
+func renderFills(f *frame, s api.Status) {
+	f.heading("Recent Fills")
+	var fills []api.Transaction
+	for _, t := range slices.Backward(s.RecentTransactions) {
+		if t.Type == "BUY" || t.Type == "SELL" {
+			fills = append(fills, t)
+		}
+		if len(fills) == maxFills {
+			break
+		}
+	}
+	if len(fills) == 0 {
+		f.line(" none")
+		return
+	}
+
+	f.line("%s", f.paint(dim, fmt.Sprintf(" %-8s %-5s %16s %14s %14s %12s  %s", "TIME", "SIDE", "QUANTITY", "PRICE", "TOTAL", "P&L", "REASON")))
+	for _, t := range fills {
+		quote := t.Quote
+		if quote == "" {
+			quote = s.CashAsset
+		}
+		places := portfolio.Precision(quote)
+
+		pnl := ""
+		if t.Type == "SELL" {
+			v := t.RealizedPnL.Float64()
+			pnl = f.signed(v, fmt.Sprintf("%+12.*f", places, v))
+		} else {
+			pnl = pad("", 12)
+		}
+		f.line(" %-8s %s %16s %14s %14s %s  %s",
+			t.Time.Local().Format(time.TimeOnly), f.side(t.Type, pad(t.Type, -5)),
+			t.Amount, t.Price.StringFixed(places), t.Total.StringFixed(places), pnl, truncate(t.Reason, f.width-80))
+	}
+}
+
+func (d *Dashboard) renderRisk(f *frame) {
+	f.heading("Risk")
+	f.line(" State %s   Max risk %.1f%% of balance per trade   Drawdown %.2f%%",
+		d.state(f), d.status.MaxRisk*100, d.status.Drawdown*100)
+
+	if d.lastLimit == nil {
+		f.line(" Limit hits 0")
+	} else {
+		l := d.lastLimit
+		detail := fmt.Sprintf("last %s on %s at %s", l.Limit, l.Action, d.lastLimitAt.Local().Format(time.TimeOnly))
+		if l.Detail != "" {
+			detail += ": " + l.Detail
+		}
+		f.line(" Limit hits %s   %s", f.paint(yellow, fmt.Sprint(d.limitHits)), truncate(detail, f.width-20))
+	}
+
+	if d.lastError == nil {
+		f.line(" Errors 0")
+	} else {
+		e := d.lastError
+		detail := fmt.Sprintf("last from %s at %s: %v", e.Component, d.lastErrorAt.Local().Format(time.TimeOnly), e.Err)
+		f.line(" Errors %s   %s", f.paint(red, fmt.Sprint(d.errors)), truncate(detail, f.width-16))
+	}
+	if dropped := d.sub.Dropped(); dropped > 0 {
+		f.line(" %s", f.paint(dim, fmt.Sprintf("%d events dropped while the screen was busy", dropped)))
+	}
+}
+
+// pad right-aligns s in width columns, or left-aligns it when width is
+// negative.
+func pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if width < 0 {
+		return s + strings.Repeat(" ", max(0, -width-n))
+	}
+	return strings.Repeat(" ", max(0, width-n)) + s
+}
+
+func truncate(s string, width int) string {
+	width = max(width, 10)
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+func age(now, t time.Time) string {
+	return now.Sub(t).Round(time.Second).String()
+}